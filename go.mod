@@ -6,6 +6,7 @@ require (
 	github.com/golang-jwt/jwt/v4 v4.4.2
 	github.com/knadh/koanf v1.4.3
 	github.com/labstack/echo/v4 v4.9.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/spf13/cobra v1.5.0
 	github.com/swaggest/openapi-go v0.2.22
 	go.mongodb.org/mongo-driver v1.10.2
@@ -2,11 +2,15 @@ package api
 
 import (
 	"errors"
-	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"fmt"
 	"net/http"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/request"
 
 	"github.com/MeysamBavi/http-monitoring/internal/auth"
 	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
 	"github.com/MeysamBavi/http-monitoring/internal/store"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -17,11 +21,16 @@ type AlertHandler struct {
 	Logger     *zap.Logger
 	AlertStore store.Alert
 	JwtHandler *auth.JwtHandler
+	// the jobs of the state changes of alerts are sent by the monitor, to the channels the alerts were raised on
+	NotificationJobStore store.NotificationJob
+	ChannelStore         store.Channel
 }
 
 func (h *AlertHandler) Register(group *echo.Group) {
 	group.Use(middleware.JWTWithConfig(h.JwtHandler.Config()))
+	group.GET("", h.getAll)
 	group.GET("/:id", h.get)
+	group.PATCH("/:id", h.update)
 }
 
 func (h *AlertHandler) get(c echo.Context) error {
@@ -66,9 +75,179 @@ func (h *AlertHandler) get(c echo.Context) error {
 		}
 	}
 
-	if alerts == nil {
-		alerts = make([]*model.Alert, 0)
+	filtered := make([]*model.Alert, 0, len(alerts))
+	pick := alert.StateFilter()
+	for _, a := range alerts {
+		if pick(a) {
+			filtered = append(filtered, a)
+		}
+	}
+
+	return c.JSON(http.StatusOK, filtered)
+}
+
+func (h *AlertHandler) getAll(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.Alerts
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request",
+			zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	limit := req.PageSize()
+
+	// one more alert is read to know whether there is a next page
+	alerts, err := h.AlertStore.GetByUserId(ctx, *claims.UserId, req.Filter(), req.After(), limit+1)
+	if err != nil {
+		h.Logger.Error("error getting alerts",
+			zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	page := model.AlertPage{Alerts: alerts}
+	if len(alerts) > limit {
+		page.Alerts = alerts[:limit]
+		page.NextCursor = model.CursorOf(alerts[limit-1]).String()
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+func (h *AlertHandler) update(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.AlertUpdate
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request",
+			zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	now := time.Now()
+
+	alert, err := h.AlertStore.Get(ctx, *claims.UserId, req.ParseId())
+	if err != nil {
+		return h.handleUpdateError(c, err)
+	}
+
+	if req.State != "" {
+		state := model.AlertState(req.State)
+		if !alert.CanChangeTo(state) {
+			return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("alert is %s and can not be %s", alert.CurrentState(), state))
+		}
+
+		alert, err = h.AlertStore.UpdateState(ctx, *claims.UserId, alert.Id, model.AlertStateChange{
+			State:     state,
+			ChangedBy: *claims.UserId,
+			ChangedAt: now,
+		})
+		if err != nil {
+			return h.handleUpdateError(c, err)
+		}
+
+		h.notifyStateChange(c, alert, now)
+	}
+
+	if req.Note != "" {
+		alert, err = h.AlertStore.AddNote(ctx, *claims.UserId, alert.Id, model.AlertNote{
+			Text:      req.Note,
+			CreatedBy: *claims.UserId,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return h.handleUpdateError(c, err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, alert)
+}
+
+// queues the new state of the alert for the integrations that track the incidents of the channels it was raised on.
+// failures are only logged, because the state is already changed
+func (h *AlertHandler) notifyStateChange(c echo.Context, alert *model.Alert, now time.Time) {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+	ctx := c.Request().Context()
+
+	typ := model.AlertTypeAcknowledged
+	if alert.State == model.AlertStateResolved {
+		typ = model.AlertTypeResolved
+	}
+
+	channelIds, err := h.NotificationJobStore.GetTriggeredChannelIds(ctx, *claims.UserId, []model.ID{alert.Id})
+	if err != nil {
+		h.Logger.Error("error getting channels of alert",
+			zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return
+	}
+
+	for _, channelId := range channelIds {
+		channel, err := h.ChannelStore.Get(ctx, *claims.UserId, channelId)
+		if err != nil {
+			// the channel may have been deleted since the alert was sent to it
+			h.Logger.Warn("could not get channel of alert",
+				zap.Error(err),
+				zap.Any("user_id", claims.UserId),
+				zap.Any("channel_id", channelId),
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+			continue
+		}
+
+		if !notification.FollowsStateChange(channel.Type, typ) {
+			continue
+		}
+
+		job := model.NewNotificationJob(channelId, alert, typ, model.SeverityOf(typ), nil, now)
+		if err := h.NotificationJobStore.Add(ctx, job); err != nil {
+			h.Logger.Error("error adding notification job",
+				zap.Error(err),
+				zap.Any("user_id", claims.UserId),
+				zap.Any("job", job),
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		}
+	}
+}
+
+func (h *AlertHandler) handleUpdateError(c echo.Context, err error) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var notFound store.NotFoundError
+	if errors.As(err, &notFound) {
+		h.Logger.Error("alert not found",
+			zap.Error(notFound),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusNotFound, "alert not found")
+	}
+
+	// another request changed the state of the alert after it was checked
+	var conflict store.ConflictError
+	if errors.As(err, &conflict) {
+		return echo.NewHTTPError(http.StatusConflict, conflict.Error())
 	}
 
-	return c.JSON(http.StatusOK, alerts)
+	h.Logger.Error("error updating alert",
+		zap.Error(err),
+		zap.Any("user_id", claims.UserId),
+		zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+	return echo.ErrInternalServerError
 }
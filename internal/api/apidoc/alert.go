@@ -18,7 +18,7 @@ func (d *DocGenerator) specifyAlertsGetOperation() {
 	op.
 		WithSecurity(map[string][]string{securityName: {}}).
 		WithSummary("Gets all alerts").
-		WithDescription("Gets all alerts").
+		WithDescription("Gets all alerts of a url. Alerts can be filtered by state").
 		WithID("getAlerts").
 		WithTags(alertTag)
 
@@ -31,3 +31,41 @@ func (d *DocGenerator) specifyAlertsGetOperation() {
 
 	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, alertGroup+"/{id}", op))
 }
+
+func (d *DocGenerator) specifyAlertsUpdateOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Acknowledges, resolves or adds a note to an alert").
+		WithDescription("Changes the state of an alert to acknowledged or resolved and/or adds a note to it. " +
+			"The user and time of each change are recorded. Resolved alerts can not be acknowledged").
+		WithID("updateAlert").
+		WithTags(alertTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.AlertUpdate), http.MethodPatch))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.Alert), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusConflict), http.StatusConflict))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPatch, alertGroup+"/{id}", op))
+}
+
+func (d *DocGenerator) specifyAlertsGetAllOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Gets alerts of all urls of user").
+		WithDescription("Gets alerts of all urls of user, the latest first. Alerts can be filtered using query parameters. " +
+			"The result is paginated, pass 'next_cursor' of a page as 'cursor' to get the next page").
+		WithID("getAllAlerts").
+		WithTags(alertTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.Alerts), http.MethodGet))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.AlertPage), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, alertGroup+"", op))
+}
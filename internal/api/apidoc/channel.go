@@ -0,0 +1,120 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+const (
+	channelGroup = "/channels"
+	channelTag   = "Channels"
+)
+
+func (d *DocGenerator) specifyChannelsCreateOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Creates a channel").
+		WithDescription("Creates a channel notifications can be sent to. Webhook channels receive the alert and its url as json").
+		WithID("createChannel").
+		WithTags(channelTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.Channel), http.MethodPost))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.Channel), http.StatusCreated))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, channelGroup+"", op))
+}
+
+func (d *DocGenerator) specifyChannelsGetAllOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns channels of user").
+		WithID("getAllChannels").
+		WithTags(channelTag)
+
+	d.handleError(d.reflector.SetJSONResponse(&op, new([]model.Channel), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, channelGroup+"", op))
+}
+
+func (d *DocGenerator) specifyChannelsDeleteOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Deletes a channel").
+		WithID("deleteChannel").
+		WithTags(channelTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.ChannelId), http.MethodDelete))
+	d.handleError(d.reflector.SetJSONResponse(&op, nil, http.StatusNoContent))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodDelete, channelGroup+"/{id}", op))
+}
+
+func (d *DocGenerator) specifyChannelsSetTemplateOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Overrides the message template of a channel").
+		WithDescription("Sets the template used to render the messages sent to a channel. " +
+			"Templates are executed with the message of the alert, whose data is shown by the preview endpoint. " +
+			"Besides the builtin functions, 'upper', 'lower', 'formatTime' and 'truncate' can be used. " +
+			"If a template fails when a message is sent, the default template is used instead").
+		WithID("setChannelTemplate").
+		WithTags(channelTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.ChannelTemplate), http.MethodPut))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.Channel), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPut, channelGroup+"/{id}/template", op))
+}
+
+func (d *DocGenerator) specifyChannelsResetTemplateOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Resets the message template of a channel to the default").
+		WithID("resetChannelTemplate").
+		WithTags(channelTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.ChannelId), http.MethodDelete))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.Channel), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodDelete, channelGroup+"/{id}/template", op))
+}
+
+func (d *DocGenerator) specifyChannelsPreviewTemplateOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Renders a template with sample data").
+		WithDescription("Renders a template with the message of a sample alert. " +
+			"Parse and execution errors of the template are reported in 'error'").
+		WithID("previewTemplate").
+		WithTags(channelTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.TemplatePreview), http.MethodPost))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(notification.Preview), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, channelGroup+"/template-preview", op))
+}
@@ -36,12 +36,52 @@ func (d *DocGenerator) OpenAPISpecAsYaml() ([]byte, error) {
 func (d *DocGenerator) specifyOperations() {
 	d.specifyUsersCreateOperation()
 	d.specifyUsersLoginOperation()
+	d.specifyUsersUpdatePreferencesOperation()
 
 	d.specifyUrlsCreateOperation()
 	d.specifyUrlsGetAllOperation()
 	d.specifyUrlsGetDayStatsOperation()
+	d.specifyUrlsGetStatsOperation()
+	d.specifyUrlsGetUptimeOperation()
+	d.specifyUrlsGetRunsOperation()
+	d.specifyUrlsPreviewScheduleOperation()
+	d.specifyUrlsPauseOperation()
+	d.specifyUrlsResumeOperation()
 
+	d.specifyAlertsGetAllOperation()
 	d.specifyAlertsGetOperation()
+	d.specifyAlertsUpdateOperation()
+
+	d.specifyIncidentsGetAllOperation()
+	d.specifyIncidentsGetOperation()
+
+	d.specifyMaintenancesCreateOperation()
+	d.specifyMaintenancesGetAllOperation()
+	d.specifyMaintenancesDeleteOperation()
+
+	d.specifySilencesCreateOperation()
+	d.specifySilencesGetAllOperation()
+	d.specifySilencesExpireOperation()
+
+	d.specifyChannelsCreateOperation()
+	d.specifyChannelsGetAllOperation()
+	d.specifyChannelsDeleteOperation()
+	d.specifyChannelsSetTemplateOperation()
+	d.specifyChannelsResetTemplateOperation()
+	d.specifyChannelsPreviewTemplateOperation()
+
+	d.specifyEscalationPoliciesCreateOperation()
+	d.specifyEscalationPoliciesGetAllOperation()
+	d.specifyEscalationPoliciesDeleteOperation()
+
+	d.specifyRoutingRulesCreateOperation()
+	d.specifyRoutingRulesGetAllOperation()
+	d.specifyRoutingRulesDeleteOperation()
+	d.specifyRoutingRulesDryRunOperation()
+
+	d.specifyNotificationJobsGetAllOperation()
+	d.specifyNotificationJobsGetOperation()
+	d.specifyNotificationJobsRedriveOperation()
 }
 
 func (d *DocGenerator) handleError(err error) {
@@ -0,0 +1,64 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+const (
+	escalationPolicyGroup = "/escalation-policies"
+	escalationPolicyTag   = "Escalation Policies"
+)
+
+func (d *DocGenerator) specifyEscalationPoliciesCreateOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Creates an escalation policy").
+		WithDescription("Creates an escalation policy. Each step notifies its channels when 'after' has passed since the alert was issued, " +
+			"until the alert is acknowledged or resolved, or the url recovers. Silenced alerts are not escalated").
+		WithID("createEscalationPolicy").
+		WithTags(escalationPolicyTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.EscalationPolicy), http.MethodPost))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.EscalationPolicy), http.StatusCreated))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, escalationPolicyGroup+"", op))
+}
+
+func (d *DocGenerator) specifyEscalationPoliciesGetAllOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns escalation policies of user").
+		WithID("getAllEscalationPolicies").
+		WithTags(escalationPolicyTag)
+
+	d.handleError(d.reflector.SetJSONResponse(&op, new([]model.EscalationPolicy), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, escalationPolicyGroup+"", op))
+}
+
+func (d *DocGenerator) specifyEscalationPoliciesDeleteOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Deletes an escalation policy").
+		WithID("deleteEscalationPolicy").
+		WithTags(escalationPolicyTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.EscalationPolicyId), http.MethodDelete))
+	d.handleError(d.reflector.SetJSONResponse(&op, nil, http.StatusNoContent))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodDelete, escalationPolicyGroup+"/{id}", op))
+}
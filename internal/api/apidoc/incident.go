@@ -0,0 +1,51 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+const (
+	incidentGroup = "/incidents"
+	incidentTag   = "Incidents"
+)
+
+func (d *DocGenerator) specifyIncidentsGetAllOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns incidents of user").
+		WithDescription("Returns incidents of user, the latest first. An incident is opened when a url goes down and resolved when it recovers. " +
+			"Incidents can be filtered using query parameters. Timelines are not included").
+		WithID("getAllIncidents").
+		WithTags(incidentTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.Incidents), http.MethodGet))
+	d.handleError(d.reflector.SetJSONResponse(&op, new([]model.Incident), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, incidentGroup+"", op))
+}
+
+func (d *DocGenerator) specifyIncidentsGetOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns an incident with its timeline").
+		WithDescription("Returns an incident with its timeline of failed checks and alerts").
+		WithID("getIncident").
+		WithTags(incidentTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.Incident), http.MethodGet))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.Incident), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, incidentGroup+"/{id}", op))
+}
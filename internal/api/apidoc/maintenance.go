@@ -0,0 +1,65 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+const (
+	maintenanceGroup = "/maintenances"
+	maintenanceTag   = "Maintenances"
+)
+
+func (d *DocGenerator) specifyMaintenancesCreateOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Creates a maintenance window").
+		WithDescription("Creates a one-off window using 'start' and 'end', or a recurring window using 'cron' and 'duration', " +
+			"for a url or all urls with a tag. No alerts are raised during the window and it can be excluded from uptime reports. " +
+			"If 'skip_checks' is set, the urls are not checked during the window").
+		WithID("createMaintenance").
+		WithTags(maintenanceTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.Maintenance), http.MethodPost))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.Maintenance), http.StatusCreated))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, maintenanceGroup+"", op))
+}
+
+func (d *DocGenerator) specifyMaintenancesGetAllOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns maintenance windows of user").
+		WithID("getAllMaintenances").
+		WithTags(maintenanceTag)
+
+	d.handleError(d.reflector.SetJSONResponse(&op, new([]model.Maintenance), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, maintenanceGroup+"", op))
+}
+
+func (d *DocGenerator) specifyMaintenancesDeleteOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Deletes a maintenance window").
+		WithID("deleteMaintenance").
+		WithTags(maintenanceTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.MaintenanceId), http.MethodDelete))
+	d.handleError(d.reflector.SetJSONResponse(&op, nil, http.StatusNoContent))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodDelete, maintenanceGroup+"/{id}", op))
+}
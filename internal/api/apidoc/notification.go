@@ -0,0 +1,70 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+const (
+	notificationJobGroup = "/notification-jobs"
+	notificationJobTag   = "Notification Jobs"
+)
+
+func (d *DocGenerator) specifyNotificationJobsGetAllOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns notification jobs of user").
+		WithDescription("Returns notification jobs of user, the latest first. A job is created for each channel an alert is sent to. " +
+			"Failed jobs are retried with backoff, and are dead after too many failed attempts").
+		WithID("getAllNotificationJobs").
+		WithTags(notificationJobTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.NotificationJobs), http.MethodGet))
+	d.handleError(d.reflector.SetJSONResponse(&op, new([]model.NotificationJob), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, notificationJobGroup+"", op))
+}
+
+func (d *DocGenerator) specifyNotificationJobsGetOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns a notification job").
+		WithDescription("Returns a notification job with its attempts and last error").
+		WithID("getNotificationJob").
+		WithTags(notificationJobTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.NotificationJobId), http.MethodGet))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.NotificationJob), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, notificationJobGroup+"/{id}", op))
+}
+
+func (d *DocGenerator) specifyNotificationJobsRedriveOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Re-drives a dead notification job").
+		WithDescription("Makes a dead notification job pending with no attempts, so it is sent again").
+		WithID("redriveNotificationJob").
+		WithTags(notificationJobTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.NotificationJobId), http.MethodPost))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.NotificationJob), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusConflict), http.StatusConflict))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, notificationJobGroup+"/{id}/redrive", op))
+}
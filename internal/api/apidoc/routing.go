@@ -0,0 +1,85 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+const (
+	routingRuleGroup = "/routing-rules"
+	routingRuleTag   = "Routing Rules"
+)
+
+func (d *DocGenerator) specifyRoutingRulesCreateOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Creates a routing rule").
+		WithDescription("Creates a rule that selects the channels notified of alerts matching its url ids, tags, types and severities. " +
+			"Empty matchers match everything. Rules are evaluated by 'order' and the channels of all matching rules are notified, " +
+			"until a matching rule with 'stop' is reached").
+		WithID("createRoutingRule").
+		WithTags(routingRuleTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.RoutingRule), http.MethodPost))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.RoutingRule), http.StatusCreated))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, routingRuleGroup+"", op))
+}
+
+func (d *DocGenerator) specifyRoutingRulesGetAllOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns routing rules of user").
+		WithDescription("Returns routing rules of user in evaluation order").
+		WithID("getAllRoutingRules").
+		WithTags(routingRuleTag)
+
+	d.handleError(d.reflector.SetJSONResponse(&op, new([]model.RoutingRule), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, routingRuleGroup+"", op))
+}
+
+func (d *DocGenerator) specifyRoutingRulesDeleteOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Deletes a routing rule").
+		WithID("deleteRoutingRule").
+		WithTags(routingRuleTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.RoutingRuleId), http.MethodDelete))
+	d.handleError(d.reflector.SetJSONResponse(&op, nil, http.StatusNoContent))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodDelete, routingRuleGroup+"/{id}", op))
+}
+
+func (d *DocGenerator) specifyRoutingRulesDryRunOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Shows how a hypothetical alert would be routed").
+		WithDescription("Returns the matching rules and the channels that would be notified of an alert of a url, " +
+			"or of a hypothetical url with the given tags. Nothing is sent").
+		WithID("dryRunRoutingRules").
+		WithTags(routingRuleTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.RoutingDryRun), http.MethodPost))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.RoutingDryRun), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, routingRuleGroup+"/dry-run", op))
+}
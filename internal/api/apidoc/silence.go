@@ -0,0 +1,68 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/openapi-go/openapi3"
+)
+
+const (
+	silenceGroup = "/silences"
+	silenceTag   = "Silences"
+)
+
+func (d *DocGenerator) specifySilencesCreateOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Creates a silence").
+		WithDescription("Creates a silence for the urls matched by all of its matchers. " +
+			"Alerts raised for a silenced url are stored with 'silenced_by' set, but are not notified").
+		WithID("createSilence").
+		WithTags(silenceTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.Silence), http.MethodPost))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.Silence), http.StatusCreated))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, silenceGroup+"", op))
+}
+
+func (d *DocGenerator) specifySilencesGetAllOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns silences of user").
+		WithDescription("Returns silences of user, the latest first. Expired silences are only returned if requested").
+		WithID("getAllSilences").
+		WithTags(silenceTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.Silences), http.MethodGet))
+	d.handleError(d.reflector.SetJSONResponse(&op, new([]model.Silence), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, silenceGroup+"", op))
+}
+
+func (d *DocGenerator) specifySilencesExpireOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Expires a silence").
+		WithDescription("Ends a silence now. The silence is kept and returned with its new end").
+		WithID("expireSilence").
+		WithTags(silenceTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.SilenceId), http.MethodDelete))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.Silence), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodDelete, silenceGroup+"/{id}", op))
+}
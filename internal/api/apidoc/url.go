@@ -6,6 +6,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/swaggest/openapi-go/openapi3"
 	"net/http"
+	"time"
 )
 
 const (
@@ -63,3 +64,112 @@ func (d *DocGenerator) specifyUrlsGetDayStatsOperation() {
 
 	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, urlGroup+"/{id}/stats", op))
 }
+
+func (d *DocGenerator) specifyUrlsGetStatsOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns url monitoring stats in time buckets").
+		WithDescription("Returns monitoring stats for a specific url in minute, hour or day buckets within a time range. " +
+			"Minute and hour buckets are only kept for a limited time, older data is available in coarser buckets").
+		WithID("getStats").
+		WithTags(urlTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.Stats), http.MethodGet))
+	d.handleError(d.reflector.SetJSONResponse(&op, new([]model.Stat), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, urlGroup+"/{id}/stats/buckets", op))
+}
+
+func (d *DocGenerator) specifyUrlsGetUptimeOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns uptime report of url").
+		WithDescription("Returns uptime percentage, downtime, number of incidents, MTTR and MTBF of a url in the last 24h, 7d, 30d or a custom window. " +
+			"The report is computed from the finest stats still kept for the window. Maintenance windows of the url are excluded unless 'exclude_maintenance' is false").
+		WithID("getUptime").
+		WithTags(urlTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.Uptime), http.MethodGet))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.UptimeReport), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, urlGroup+"/{id}/uptime", op))
+}
+
+func (d *DocGenerator) specifyUrlsGetRunsOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Returns next check times of url").
+		WithDescription("Returns the next times the url is checked, from its interval or schedule. " +
+			"Calls of interval urls are offset within the interval by the jitter of the monitor. A paused url has no runs").
+		WithID("getUrlRuns").
+		WithTags(urlTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.UrlRuns), http.MethodGet))
+	d.handleError(d.reflector.SetJSONResponse(&op, new([]time.Time), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodGet, urlGroup+"/{id}/runs", op))
+}
+
+func (d *DocGenerator) specifyUrlsPreviewScheduleOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Previews a schedule").
+		WithDescription("Validates a schedule of cron expressions and returns its next run times in its time zone").
+		WithID("previewSchedule").
+		WithTags(urlTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.SchedulePreview), http.MethodPost))
+	d.handleError(d.reflector.SetJSONResponse(&op, new([]time.Time), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, urlGroup+"/schedule/preview", op))
+}
+
+func (d *DocGenerator) specifyUrlsPauseOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Pauses monitoring of a url").
+		WithDescription("Pauses monitoring of a url. A paused url is not checked until it is resumed").
+		WithID("pauseUrl").
+		WithTags(urlTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.UrlId), http.MethodPost))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.URL), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, urlGroup+"/{id}/pause", op))
+}
+
+func (d *DocGenerator) specifyUrlsResumeOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Resumes monitoring of a url").
+		WithDescription("Resumes monitoring of a paused url").
+		WithID("resumeUrl").
+		WithTags(urlTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.UrlId), http.MethodPost))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.URL), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, urlGroup+"/{id}/resume", op))
+}
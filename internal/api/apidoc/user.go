@@ -44,3 +44,21 @@ func (d *DocGenerator) specifyUsersLoginOperation() {
 
 	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPost, userGroup+"/login", op))
 }
+
+func (d *DocGenerator) specifyUsersUpdatePreferencesOperation() {
+	op := openapi3.Operation{}
+	op.
+		WithSecurity(map[string][]string{securityName: {}}).
+		WithSummary("Updates preferences of user").
+		WithDescription("Updates preferences of user, such as the default time zone of stats queries").
+		WithID("updateUserPreferences").
+		WithTags(userTag)
+
+	d.handleError(d.reflector.SetRequest(&op, new(request.Preferences), http.MethodPatch))
+	d.handleError(d.reflector.SetJSONResponse(&op, new(model.User), http.StatusOK))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusBadRequest), http.StatusBadRequest))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized))
+	d.handleError(d.reflector.SetJSONResponse(&op, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound))
+
+	d.handleError(d.reflector.SpecEns().AddOperation(http.MethodPatch, userGroup+"/me", op))
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/auth"
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
+)
+
+type ChannelHandler struct {
+	Logger       *zap.Logger
+	ChannelStore store.Channel
+	JwtHandler   *auth.JwtHandler
+}
+
+func (h *ChannelHandler) Register(group *echo.Group) {
+	group.Use(middleware.JWTWithConfig(h.JwtHandler.Config()))
+	group.GET("", h.getAll)
+	group.POST("", h.create)
+	group.DELETE("/:id", h.delete)
+	group.PUT("/:id/template", h.setTemplate)
+	group.DELETE("/:id/template", h.resetTemplate)
+	group.POST("/template-preview", h.previewTemplate)
+}
+
+func (h *ChannelHandler) create(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.Channel
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	channel := req.Model(*claims.UserId)
+
+	if err := h.ChannelStore.Add(ctx, channel); err != nil {
+		h.Logger.Error("error adding channel", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusCreated, channel)
+}
+
+func (h *ChannelHandler) getAll(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	ctx := c.Request().Context()
+	channels, err := h.ChannelStore.GetByUserId(ctx, *claims.UserId)
+
+	if err != nil {
+		h.Logger.Error("error getting channels", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, channels)
+}
+
+func (h *ChannelHandler) delete(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.ChannelId
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	err := h.ChannelStore.Delete(ctx, *claims.UserId, req.ParseId())
+
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "channel not found")
+		}
+
+		h.Logger.Error("error deleting channel", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *ChannelHandler) setTemplate(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.ChannelTemplate
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return h.updateTemplate(c, req.ParseId(), req.Template())
+}
+
+func (h *ChannelHandler) resetTemplate(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.ChannelId
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return h.updateTemplate(c, req.ParseId(), nil)
+}
+
+func (h *ChannelHandler) updateTemplate(c echo.Context, id model.ID, template *model.Template) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	ctx := c.Request().Context()
+	channel, err := h.ChannelStore.SetTemplate(ctx, *claims.UserId, id, template)
+
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "channel not found")
+		}
+
+		h.Logger.Error("error setting channel template", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, channel)
+}
+
+func (h *ChannelHandler) previewTemplate(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.TemplatePreview
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, notification.PreviewOf(req.Template(), time.Now()))
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/auth"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
+)
+
+type EscalationPolicyHandler struct {
+	Logger                *zap.Logger
+	EscalationPolicyStore store.EscalationPolicy
+	ChannelStore          store.Channel
+	JwtHandler            *auth.JwtHandler
+}
+
+func (h *EscalationPolicyHandler) Register(group *echo.Group) {
+	group.Use(middleware.JWTWithConfig(h.JwtHandler.Config()))
+	group.GET("", h.getAll)
+	group.POST("", h.create)
+	group.DELETE("/:id", h.delete)
+}
+
+func (h *EscalationPolicyHandler) create(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.EscalationPolicy
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	for _, channelId := range req.ChannelIds() {
+		if _, err := h.ChannelStore.Get(ctx, *claims.UserId, channelId); err != nil {
+			var notFound store.NotFoundError
+			if errors.As(err, &notFound) {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("channel %v not found", channelId))
+			}
+
+			h.Logger.Error("error getting channel", zap.Error(err),
+				zap.Any("user_id", claims.UserId),
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+			return echo.ErrInternalServerError
+		}
+	}
+
+	policy := req.Model(*claims.UserId)
+	if err := h.EscalationPolicyStore.Add(ctx, policy); err != nil {
+		h.Logger.Error("error adding escalation policy", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusCreated, policy)
+}
+
+func (h *EscalationPolicyHandler) getAll(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	ctx := c.Request().Context()
+	policies, err := h.EscalationPolicyStore.GetByUserId(ctx, *claims.UserId)
+
+	if err != nil {
+		h.Logger.Error("error getting escalation policies", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, policies)
+}
+
+func (h *EscalationPolicyHandler) delete(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.EscalationPolicyId
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	err := h.EscalationPolicyStore.Delete(ctx, *claims.UserId, req.ParseId())
+
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "escalation policy not found")
+		}
+
+		h.Logger.Error("error deleting escalation policy", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/auth"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
+)
+
+type IncidentHandler struct {
+	Logger        *zap.Logger
+	IncidentStore store.Incident
+	JwtHandler    *auth.JwtHandler
+}
+
+func (h *IncidentHandler) Register(group *echo.Group) {
+	group.Use(middleware.JWTWithConfig(h.JwtHandler.Config()))
+	group.GET("", h.getAll)
+	group.GET("/:id", h.get)
+}
+
+func (h *IncidentHandler) getAll(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.Incidents
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	incidents, err := h.IncidentStore.GetByUserId(ctx, *claims.UserId, req.Filter())
+
+	if err != nil {
+		h.Logger.Error("error getting incidents", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	// timelines are only returned for a single incident
+	for _, incident := range incidents {
+		incident.Timeline = nil
+	}
+
+	return c.JSON(http.StatusOK, incidents)
+}
+
+func (h *IncidentHandler) get(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.Incident
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	incident, err := h.IncidentStore.Get(ctx, *claims.UserId, req.ParseId())
+
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			h.Logger.Error("incident not found", zap.Error(notFound),
+				zap.Any("user_id", claims.UserId),
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+			return echo.NewHTTPError(http.StatusNotFound, "incident not found")
+		}
+
+		h.Logger.Error("error getting incident", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, incident)
+}
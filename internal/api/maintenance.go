@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/auth"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
+)
+
+type MaintenanceHandler struct {
+	Logger           *zap.Logger
+	MaintenanceStore store.Maintenance
+	JwtHandler       *auth.JwtHandler
+}
+
+func (h *MaintenanceHandler) Register(group *echo.Group) {
+	group.Use(middleware.JWTWithConfig(h.JwtHandler.Config()))
+	group.GET("", h.getAll)
+	group.POST("", h.create)
+	group.DELETE("/:id", h.delete)
+}
+
+func (h *MaintenanceHandler) create(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.Maintenance
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	maintenance := req.Model(*claims.UserId)
+
+	if err := h.MaintenanceStore.Add(ctx, maintenance); err != nil {
+		h.Logger.Error("error adding maintenance", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusCreated, maintenance)
+}
+
+func (h *MaintenanceHandler) getAll(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	ctx := c.Request().Context()
+	maintenances, err := h.MaintenanceStore.GetByUserId(ctx, *claims.UserId)
+
+	if err != nil {
+		h.Logger.Error("error getting maintenances", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, maintenances)
+}
+
+func (h *MaintenanceHandler) delete(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.MaintenanceId
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	err := h.MaintenanceStore.Delete(ctx, *claims.UserId, req.ParseId())
+
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "maintenance not found")
+		}
+
+		h.Logger.Error("error deleting maintenance", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
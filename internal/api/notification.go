@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/auth"
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
+)
+
+type NotificationJobHandler struct {
+	Logger               *zap.Logger
+	NotificationJobStore store.NotificationJob
+	JwtHandler           *auth.JwtHandler
+}
+
+func (h *NotificationJobHandler) Register(group *echo.Group) {
+	group.Use(middleware.JWTWithConfig(h.JwtHandler.Config()))
+	group.GET("", h.getAll)
+	group.GET("/:id", h.get)
+	group.POST("/:id/redrive", h.redrive)
+}
+
+func (h *NotificationJobHandler) getAll(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.NotificationJobs
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	jobs, err := h.NotificationJobStore.GetByUserId(ctx, *claims.UserId, req.JobStatus(), req.PageSize())
+
+	if err != nil {
+		h.Logger.Error("error getting notification jobs", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, jobs)
+}
+
+func (h *NotificationJobHandler) get(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.NotificationJobId
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	job, err := h.NotificationJobStore.Get(ctx, *claims.UserId, req.ParseId())
+
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "notification job not found")
+		}
+
+		h.Logger.Error("error getting notification job", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+// redrive makes a dead job pending, so the monitor sends it again
+func (h *NotificationJobHandler) redrive(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.NotificationJobId
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	job, err := h.NotificationJobStore.Get(ctx, *claims.UserId, req.ParseId())
+	if err == nil && job.Status != model.NotificationJobStatusDead {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("notification job is %s, only dead jobs can be re-driven", job.Status))
+	}
+	if err == nil {
+		job, err = h.NotificationJobStore.Redrive(ctx, *claims.UserId, req.ParseId(), time.Now())
+	}
+
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "notification job not found")
+		}
+
+		h.Logger.Error("error re-driving notification job", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/auth"
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
+)
+
+type RoutingRuleHandler struct {
+	Logger           *zap.Logger
+	RoutingRuleStore store.RoutingRule
+	ChannelStore     store.Channel
+	UrlStore         store.Url
+	JwtHandler       *auth.JwtHandler
+}
+
+func (h *RoutingRuleHandler) Register(group *echo.Group) {
+	group.Use(middleware.JWTWithConfig(h.JwtHandler.Config()))
+	group.GET("", h.getAll)
+	group.POST("", h.create)
+	group.DELETE("/:id", h.delete)
+	group.POST("/dry-run", h.dryRun)
+}
+
+func (h *RoutingRuleHandler) create(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.RoutingRule
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	for _, channelId := range req.ParseChannelIds() {
+		if _, err := h.ChannelStore.Get(ctx, *claims.UserId, channelId); err != nil {
+			var notFound store.NotFoundError
+			if errors.As(err, &notFound) {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("channel %v not found", channelId))
+			}
+
+			h.Logger.Error("error getting channel", zap.Error(err),
+				zap.Any("user_id", claims.UserId),
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+			return echo.ErrInternalServerError
+		}
+	}
+
+	rule := req.Model(*claims.UserId)
+	if err := h.RoutingRuleStore.Add(ctx, rule); err != nil {
+		h.Logger.Error("error adding routing rule", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusCreated, rule)
+}
+
+func (h *RoutingRuleHandler) getAll(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	ctx := c.Request().Context()
+	rules, err := h.RoutingRuleStore.GetByUserId(ctx, *claims.UserId)
+
+	if err != nil {
+		h.Logger.Error("error getting routing rules", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, rules)
+}
+
+func (h *RoutingRuleHandler) delete(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.RoutingRuleId
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	err := h.RoutingRuleStore.Delete(ctx, *claims.UserId, req.ParseId())
+
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "routing rule not found")
+		}
+
+		h.Logger.Error("error deleting routing rule", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *RoutingRuleHandler) dryRun(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.RoutingDryRun
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	url := &model.URL{UserId: *claims.UserId, Tags: req.Tags}
+	if req.UrlId != "" {
+		urls, err := h.UrlStore.GetByUserId(ctx, *claims.UserId)
+		if err != nil {
+			h.Logger.Error("error getting user urls", zap.Error(err),
+				zap.Any("user_id", claims.UserId),
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+			return echo.ErrInternalServerError
+		}
+
+		url = nil
+		for _, u := range urls {
+			if u.Id == req.ParseUrlId() {
+				url = u
+			}
+		}
+
+		if url == nil {
+			return echo.NewHTTPError(http.StatusNotFound, "url not found")
+		}
+	}
+
+	rules, err := h.RoutingRuleStore.GetByUserId(ctx, *claims.UserId)
+	if err != nil {
+		h.Logger.Error("error getting routing rules", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	matched, channelIds := model.Route(rules, url, model.AlertType(req.Type), req.AlertSeverity())
+	result := model.RoutingDryRun{
+		Rules:    matched,
+		Channels: make([]*model.Channel, 0, len(channelIds)),
+	}
+
+	for _, channelId := range channelIds {
+		channel, err := h.ChannelStore.Get(ctx, *claims.UserId, channelId)
+		if err != nil {
+			var notFound store.NotFoundError
+			if errors.As(err, &notFound) {
+				// deleted channels are skipped when notifying too
+				continue
+			}
+
+			h.Logger.Error("error getting channel", zap.Error(err),
+				zap.Any("user_id", claims.UserId),
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+			return echo.ErrInternalServerError
+		}
+		result.Channels = append(result.Channels, channel)
+	}
+
+	return c.JSON(http.StatusOK, &result)
+}
@@ -17,12 +17,12 @@ func Setup(cfg *config.Config, logger *zap.Logger, app *echo.Echo) {
 
 	app.Use(newLoggerMiddleware(logger))
 	app.Use(middleware.RequestID())
-	registerAPIs(logger, s, jh, app)
+	registerAPIs(cfg, logger, s, jh, app)
 
 	app.Debug = cfg.Debug
 }
 
-func registerAPIs(logger *zap.Logger, s store.Store, jh *auth.JwtHandler, app *echo.Echo) {
+func registerAPIs(cfg *config.Config, logger *zap.Logger, s store.Store, jh *auth.JwtHandler, app *echo.Echo) {
 	logger = logger.Named("endpoint")
 
 	uh := UserHandler{
@@ -33,18 +33,79 @@ func registerAPIs(logger *zap.Logger, s store.Store, jh *auth.JwtHandler, app *e
 	uh.Register(app.Group("/users"))
 
 	urh := UrlHandler{
-		Logger:     logger.Named("url"),
-		UrlStore:   s.Url(),
-		JwtHandler: jh,
+		Logger:                logger.Named("url"),
+		UrlStore:              s.Url(),
+		StatStore:             s.Stat(),
+		UserStore:             s.User(),
+		AlertStore:            s.Alert(),
+		MaintenanceStore:      s.Maintenance(),
+		EscalationPolicyStore: s.EscalationPolicy(),
+		JwtHandler:            jh,
+		Monitoring:            cfg.Monitoring,
 	}
 	urh.Register(app.Group("/urls"))
 
 	ah := AlertHandler{
-		Logger:     logger.Named("alert"),
-		AlertStore: s.Alert(),
-		JwtHandler: jh,
+		Logger:               logger.Named("alert"),
+		AlertStore:           s.Alert(),
+		JwtHandler:           jh,
+		NotificationJobStore: s.NotificationJob(),
+		ChannelStore:         s.Channel(),
 	}
 	ah.Register(app.Group("/alerts"))
+
+	ih := IncidentHandler{
+		Logger:        logger.Named("incident"),
+		IncidentStore: s.Incident(),
+		JwtHandler:    jh,
+	}
+	ih.Register(app.Group("/incidents"))
+
+	mh := MaintenanceHandler{
+		Logger:           logger.Named("maintenance"),
+		MaintenanceStore: s.Maintenance(),
+		JwtHandler:       jh,
+	}
+	mh.Register(app.Group("/maintenances"))
+
+	sh := SilenceHandler{
+		Logger:       logger.Named("silence"),
+		SilenceStore: s.Silence(),
+		UserStore:    s.User(),
+		JwtHandler:   jh,
+	}
+	sh.Register(app.Group("/silences"))
+
+	ch := ChannelHandler{
+		Logger:       logger.Named("channel"),
+		ChannelStore: s.Channel(),
+		JwtHandler:   jh,
+	}
+	ch.Register(app.Group("/channels"))
+
+	eh := EscalationPolicyHandler{
+		Logger:                logger.Named("escalation"),
+		EscalationPolicyStore: s.EscalationPolicy(),
+		ChannelStore:          s.Channel(),
+		JwtHandler:            jh,
+	}
+	eh.Register(app.Group("/escalation-policies"))
+
+	rh := RoutingRuleHandler{
+		Logger:           logger.Named("routing"),
+		RoutingRuleStore: s.RoutingRule(),
+		ChannelStore:     s.Channel(),
+		UrlStore:         s.Url(),
+		JwtHandler:       jh,
+	}
+	rh.Register(app.Group("/routing-rules"))
+
+	nh := NotificationJobHandler{
+		Logger:               logger.Named("notification"),
+		NotificationJobStore: s.NotificationJob(),
+		JwtHandler:           jh,
+	}
+	nh.Register(app.Group("/notification-jobs"))
 }
 
 func getJwtHandler(cfg *config.Config) *auth.JwtHandler {
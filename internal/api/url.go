@@ -1,28 +1,45 @@
 package api
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/MeysamBavi/http-monitoring/internal/auth"
 	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/monitoring"
+	"github.com/MeysamBavi/http-monitoring/internal/report"
 	"github.com/MeysamBavi/http-monitoring/internal/request"
 	"github.com/MeysamBavi/http-monitoring/internal/store"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"go.uber.org/zap"
 	"net/http"
+	"time"
 )
 
 type UrlHandler struct {
-	Logger     *zap.Logger
-	UrlStore   store.Url
-	JwtHandler *auth.JwtHandler
+	Logger                *zap.Logger
+	UrlStore              store.Url
+	StatStore             store.Stat
+	UserStore             store.User
+	AlertStore            store.Alert
+	MaintenanceStore      store.Maintenance
+	EscalationPolicyStore store.EscalationPolicy
+	JwtHandler            *auth.JwtHandler
+	Monitoring            monitoring.Config
 }
 
 func (h *UrlHandler) Register(group *echo.Group) {
 	group.Use(middleware.JWTWithConfig(h.JwtHandler.Config()))
 	group.GET("", h.getAll)
 	group.POST("", h.create)
+	group.POST("/schedule/preview", h.previewSchedule)
 	group.GET("/:id/stats", h.getDayStats)
+	group.GET("/:id/stats/buckets", h.getStats)
+	group.GET("/:id/uptime", h.getUptime)
+	group.GET("/:id/runs", h.getRuns)
+	group.POST("/:id/pause", h.pause)
+	group.POST("/:id/resume", h.resume)
 }
 
 func (h *UrlHandler) create(c echo.Context) error {
@@ -42,11 +59,33 @@ func (h *UrlHandler) create(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
+	if policyId := req.ParseEscalationPolicyId(); policyId != "" {
+		if _, err := h.EscalationPolicyStore.Get(ctx, *claims.UserId, policyId); err != nil {
+			var notFound store.NotFoundError
+			if errors.As(err, &notFound) {
+				return echo.NewHTTPError(http.StatusBadRequest, "escalation policy not found")
+			}
+
+			h.Logger.Error("error getting escalation policy", zap.Error(err),
+				zap.Any("user_id", claims.UserId),
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+			return echo.ErrInternalServerError
+		}
+	}
+
 	url := &model.URL{
-		UserId:    *claims.UserId,
-		Url:       req.Url,
-		Threshold: req.Threshold,
-		Interval:  req.Interval,
+		UserId:             *claims.UserId,
+		Url:                req.Url,
+		Threshold:          req.Threshold,
+		Interval:           req.Interval,
+		Schedule:           req.Schedule,
+		AlertPolicy:        req.AlertPolicy,
+		FailingPolicy:      req.FailingPolicy,
+		Tags:               req.Tags,
+		EscalationPolicyId: req.ParseEscalationPolicyId(),
+	}
+	if url.Tags == nil {
+		url.Tags = make([]string, 0)
 	}
 
 	err := h.UrlStore.Add(ctx, url)
@@ -61,6 +100,69 @@ func (h *UrlHandler) create(c echo.Context) error {
 	return c.JSON(http.StatusCreated, url)
 }
 
+func (h *UrlHandler) previewSchedule(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.SchedulePreview
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, req.Runs(time.Now()))
+}
+
+// returns the next call times of the url, as the monitor schedules them
+func (h *UrlHandler) getRuns(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.UrlRuns
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding the request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	urls, err := h.UrlStore.GetByUserId(ctx, *claims.UserId)
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "url not found")
+		}
+
+		h.Logger.Error("error getting user urls", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	for _, url := range urls {
+		if url.Id != req.ParseUrlId() {
+			continue
+		}
+
+		runs := make([]time.Time, 0)
+		if !url.Paused {
+			runs = monitoring.NextRuns(*url, h.Monitoring.Jitter, time.Now(), req.RunCount())
+		}
+		return c.JSON(http.StatusOK, runs)
+	}
+
+	return echo.NewHTTPError(http.StatusNotFound, "url not found")
+}
+
 func (h *UrlHandler) getAll(c echo.Context) error {
 	claims := h.JwtHandler.ParseToUserClaims(c)
 
@@ -122,5 +224,234 @@ func (h *UrlHandler) getDayStats(c echo.Context) error {
 		return echo.ErrInternalServerError
 	}
 
+	loc, err := h.location(c, dayStats.TimeZone, dayStats.Location)
+	if err != nil {
+		h.Logger.Error("error getting user time zone", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	for i := range stats {
+		stats[i].Start = stats[i].Date.Start().In(loc)
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+func (h *UrlHandler) getStats(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.Stats
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding the request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	from, to := req.Range()
+	stats, err := h.StatStore.Get(ctx, *claims.UserId, req.ParseUrlId(), req.ParseResolution(), from, to)
+
+	if err != nil {
+		h.Logger.Error("error getting stats", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	loc, err := h.location(c, req.TimeZone, req.Location)
+	if err != nil {
+		h.Logger.Error("error getting user time zone", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	for i := range stats {
+		stats[i].Start = stats[i].Start.In(loc)
+	}
+
 	return c.JSON(http.StatusOK, stats)
 }
+
+// returns the requested time zone, falling back to the time zone of the user.
+// locate loads the requested time zone, or the given one if none was requested
+func (h *UrlHandler) location(c echo.Context, timeZone string, locate func(userTimeZone string) *time.Location) (*time.Location, error) {
+	if timeZone != "" {
+		return locate(""), nil
+	}
+
+	claims := h.JwtHandler.ParseToUserClaims(c)
+	user, err := h.UserStore.Get(c.Request().Context(), *claims.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return locate(user.TimeZone), nil
+}
+
+// the most alerts a report counts. alerts of a url are limited by its cooldown, so a window rarely has this many
+const maxReportAlerts = 10_000
+
+func (h *UrlHandler) getUptime(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.Uptime
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding the request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	url, err := h.userUrl(ctx, *claims.UserId, req.ParseUrlId())
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "url not found")
+		}
+
+		h.Logger.Error("error getting user urls", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	// the same now is used for the window and the retention, so a window as long as a retention uses its buckets
+	now := time.Now()
+	window := req.TimeWindow(now)
+	resolution := h.Monitoring.FinestResolution(window.Start, now)
+
+	stats, err := h.StatStore.Get(ctx, *claims.UserId, req.ParseUrlId(), resolution, resolution.Truncate(window.Start), window.End)
+	if err != nil {
+		h.Logger.Error("error getting stats", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	alerts, err := h.AlertStore.GetByUserId(ctx, *claims.UserId, store.AlertFilter{
+		UrlId: &url.Id,
+		From:  &window.Start,
+		To:    &window.End,
+	}, nil, maxReportAlerts)
+	if err != nil {
+		h.Logger.Error("error getting alerts", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	var excluded []model.TimeWindow
+	if req.ExcludesMaintenance() {
+		excluded, err = h.maintenanceWindows(c, url, window)
+		if errors.Is(err, model.ErrTooManyOccurrences) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if err != nil {
+			h.Logger.Error("error getting maintenance windows", zap.Error(err),
+				zap.Any("user_id", claims.UserId),
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+			return echo.ErrInternalServerError
+		}
+	}
+
+	r := report.Uptime(stats, alerts, window, excluded)
+	r.UrlId = req.ParseUrlId()
+	r.Resolution = resolution
+
+	return c.JSON(http.StatusOK, &r)
+}
+
+// returns the url of the user with the id, or a store.NotFoundError if the user has no such url
+func (h *UrlHandler) userUrl(ctx context.Context, userId model.ID, id model.ID) (*model.URL, error) {
+	urls, err := h.UrlStore.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, url := range urls {
+		if url.Id == id {
+			return url, nil
+		}
+	}
+
+	return nil, store.NewNotFoundErrorM(fmt.Sprintf("user has no url with id %v", id))
+}
+
+// returns the occurrences of the maintenance windows of the url in window
+func (h *UrlHandler) maintenanceWindows(c echo.Context, url *model.URL, window model.TimeWindow) ([]model.TimeWindow, error) {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+	ctx := c.Request().Context()
+
+	maintenances, err := h.MaintenanceStore.GetByUserId(ctx, *claims.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make([]model.TimeWindow, 0)
+	for _, m := range maintenances {
+		if !m.AppliesTo(url) {
+			continue
+		}
+		occurrences, err := m.Occurrences(window.Start, window.End)
+		if err != nil {
+			return nil, err
+		}
+		excluded = append(excluded, occurrences...)
+	}
+
+	return excluded, nil
+}
+
+func (h *UrlHandler) pause(c echo.Context) error {
+	return h.setPaused(c, true)
+}
+
+func (h *UrlHandler) resume(c echo.Context) error {
+	return h.setPaused(c, false)
+}
+
+func (h *UrlHandler) setPaused(c echo.Context, paused bool) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.UrlId
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding the request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	url, err := h.UrlStore.SetPaused(ctx, *claims.UserId, req.ParseId(), paused)
+
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "url not found")
+		}
+
+		h.Logger.Error("error pausing or resuming url", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, url)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/MeysamBavi/http-monitoring/internal/request"
 	"github.com/MeysamBavi/http-monitoring/internal/store"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	"go.uber.org/zap"
 )
 
@@ -21,6 +22,7 @@ type UserHandler struct {
 func (h *UserHandler) Register(group *echo.Group) {
 	group.POST("", h.create)
 	group.POST("/login", h.login)
+	group.PATCH("/me", h.updatePreferences, middleware.JWTWithConfig(h.JwtHandler.Config()))
 }
 
 func (h *UserHandler) create(c echo.Context) error {
@@ -104,3 +106,40 @@ func (h *UserHandler) login(c echo.Context) error {
 
 	return c.String(http.StatusOK, token)
 }
+
+func (h *UserHandler) updatePreferences(c echo.Context) error {
+	claims := h.JwtHandler.ParseToUserClaims(c)
+
+	var req request.Preferences
+
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error("error binding request", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	user, err := h.UserStore.SetTimeZone(ctx, *claims.UserId, req.TimeZone)
+
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			h.Logger.Error("user not found", zap.Error(notFound),
+				zap.Any("user_id", claims.UserId),
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+
+		h.Logger.Error("error updating user", zap.Error(err),
+			zap.Any("user_id", claims.UserId),
+			zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,124 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a clock whose time only changes when it is advanced. its timers fire when the time passes their deadline
+type Fake struct {
+	mutex  sync.Mutex
+	armed  *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.armed = sync.NewCond(&f.mutex)
+	return f
+}
+
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.now
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+
+	f.mutex.Lock()
+	f.timers = append(f.timers, t)
+	f.mutex.Unlock()
+
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the time forward by d and fires the timers that are due, the earliest first
+func (f *Fake) Advance(d time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.now = f.now.Add(d)
+	f.fire()
+}
+
+// WaitForTimers blocks until n timers are waiting to fire. it is used to advance the time
+// only after a goroutine has gone to sleep, so the goroutine does not miss the advance
+func (f *Fake) WaitForTimers(n int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for f.waiting() < n {
+		f.armed.Wait()
+	}
+}
+
+func (f *Fake) waiting() int {
+	n := 0
+	for _, t := range f.timers {
+		if t.active {
+			n++
+		}
+	}
+	return n
+}
+
+// fires the due timers. called while holding the lock
+func (f *Fake) fire() {
+	due := make([]*fakeTimer, 0)
+	for _, t := range f.timers {
+		if t.active && !t.deadline.After(f.now) {
+			due = append(due, t)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].deadline.Before(due[j].deadline)
+	})
+
+	for _, t := range due {
+		t.active = false
+		select {
+		case t.c <- f.now:
+		default:
+		}
+	}
+}
+
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+	active   bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	wasActive := t.active
+	t.deadline = t.clock.now.Add(d)
+	t.active = true
+	t.clock.fire()
+	t.clock.armed.Broadcast()
+
+	return wasActive
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	wasActive := t.active
+	t.active = false
+	return wasActive
+}
@@ -0,0 +1,55 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/clock"
+)
+
+func TestFakeTimers(t *testing.T) {
+	start := time.Date(2022, 10, 8, 12, 0, 0, 0, time.UTC)
+	c := clock.NewFake(start)
+
+	timer := c.NewTimer(time.Minute)
+	c.WaitForTimers(1)
+
+	c.Advance(59 * time.Second)
+	select {
+	case <-timer.C():
+		t.Fatalf("timer fired before its deadline")
+	default:
+	}
+
+	c.Advance(time.Second)
+	select {
+	case at := <-timer.C():
+		if !at.Equal(start.Add(time.Minute)) {
+			t.Fatalf("unexpected fire time: %v", at)
+		}
+	default:
+		t.Fatalf("timer did not fire at its deadline")
+	}
+
+	if timer.Reset(time.Minute) {
+		t.Fatalf("a fired timer should not be active")
+	}
+	if !timer.Stop() {
+		t.Fatalf("a reset timer should be active")
+	}
+
+	c.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Fatalf("stopped timer fired")
+	default:
+	}
+
+	// a timer reset to a passed deadline fires immediately
+	timer.Reset(0)
+	select {
+	case <-timer.C():
+	default:
+		t.Fatalf("timer did not fire")
+	}
+}
@@ -88,6 +88,200 @@ func Migrate(cfg *config.Config, logger *zap.Logger, db *mongo.Database) {
 
 		logger.Info("database index created", zap.Any("index", idx))
 	}
+
+	{
+		// supports the user-wide alert feed, which is sorted by issue time
+		idx, err := db.Collection(cfg.Database.AlertCollection).Indexes().CreateOne(
+			context.Background(),
+			mongo.IndexModel{
+				Keys: bson.D{
+					{Key: "user_id", Value: 1},
+					{Key: "issued_at", Value: -1},
+					{Key: "_id", Value: -1},
+				},
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create alert feed index", zap.Error(err))
+		}
+
+		logger.Info("database index created", zap.Any("index", idx))
+	}
+
+	{
+		idx, err := db.Collection(cfg.Database.StatCollection).Indexes().CreateOne(
+			context.Background(),
+			mongo.IndexModel{
+				Keys: bson.D{
+					{Key: "user_id", Value: 1},
+					{Key: "url_id", Value: 1},
+					{Key: "resolution", Value: 1},
+					{Key: "start", Value: 1},
+				},
+				Options: options.Index().SetUnique(true),
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create stat bucket index", zap.Error(err))
+		}
+
+		logger.Info("database index created", zap.Any("index", idx))
+	}
+
+	{
+		// downsamples fine-grained stats by removing buckets past their retention
+		idx, err := db.Collection(cfg.Database.StatCollection).Indexes().CreateOne(
+			context.Background(),
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create stat ttl index", zap.Error(err))
+		}
+
+		logger.Info("database index created", zap.Any("index", idx))
+	}
+
+	{
+		idx, err := db.Collection(cfg.Database.IncidentCollection).Indexes().CreateMany(
+			context.Background(),
+			[]mongo.IndexModel{
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "started_at", Value: -1}}},
+				{Keys: bson.D{{Key: "state", Value: 1}}},
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create incident indexes", zap.Error(err))
+		}
+
+		logger.Info("database indexes created", zap.Any("indexes", idx))
+	}
+
+	{
+		idx, err := db.Collection(cfg.Database.MaintenanceCollection).Indexes().CreateOne(
+			context.Background(),
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "user_id", Value: 1}},
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create maintenance user id index", zap.Error(err))
+		}
+
+		logger.Info("database index created", zap.Any("index", idx))
+	}
+
+	{
+		idx, err := db.Collection(cfg.Database.SilenceCollection).Indexes().CreateOne(
+			context.Background(),
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "ends_at", Value: 1}},
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create silence index", zap.Error(err))
+		}
+
+		logger.Info("database index created", zap.Any("index", idx))
+	}
+
+	{
+		idx, err := db.Collection(cfg.Database.ChannelCollection).Indexes().CreateOne(
+			context.Background(),
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "user_id", Value: 1}},
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create channel user id index", zap.Error(err))
+		}
+
+		logger.Info("database index created", zap.Any("index", idx))
+	}
+
+	{
+		idx, err := db.Collection(cfg.Database.EscalationPolicyCollection).Indexes().CreateOne(
+			context.Background(),
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "user_id", Value: 1}},
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create escalation policy user id index", zap.Error(err))
+		}
+
+		logger.Info("database index created", zap.Any("index", idx))
+	}
+
+	{
+		idx, err := db.Collection(cfg.Database.EscalationCollection).Indexes().CreateOne(
+			context.Background(),
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "state", Value: 1}, {Key: "next_at", Value: 1}},
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create escalation index", zap.Error(err))
+		}
+
+		logger.Info("database index created", zap.Any("index", idx))
+	}
+
+	{
+		idx, err := db.Collection(cfg.Database.RoutingRuleCollection).Indexes().CreateOne(
+			context.Background(),
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "order", Value: 1}},
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create routing rule index", zap.Error(err))
+		}
+
+		logger.Info("database index created", zap.Any("index", idx))
+	}
+
+	{
+		idx, err := db.Collection(cfg.Database.NotificationJobCollection).Indexes().CreateOne(
+			context.Background(),
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create notification job index", zap.Error(err))
+		}
+
+		logger.Info("database index created", zap.Any("index", idx))
+	}
+
+	{
+		idx, err := db.Collection(cfg.Database.NotificationJobCollection).Indexes().CreateOne(
+			context.Background(),
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			},
+		)
+
+		if err != nil {
+			logger.Fatal("cannot create notification job index", zap.Error(err))
+		}
+
+		logger.Info("database index created", zap.Any("index", idx))
+	}
 }
 
 func New(cfg *config.Config, logger *zap.Logger) *cobra.Command {
@@ -1,7 +1,9 @@
 package monitor
 
 import (
+	"expvar"
 	"github.com/MeysamBavi/http-monitoring/internal/cmd/migrate"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -46,11 +48,21 @@ func main(cfg *config.Config, logger *zap.Logger, migrateFirst bool) {
 
 	scheduler := monitoring.NewScheduler(
 		logger.Named("scheduler"),
-		cfg.Monitoring.NumberOfWorkers,
-		cfg.Monitoring.RequestTimeout,
+		cfg.Monitoring,
 		s,
 	)
 
+	if addr := cfg.Monitoring.MetricsAddress; addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/debug/vars", expvar.Handler())
+		go func() {
+			logger.Info("serving metrics", zap.String("address", addr))
+			if err := http.ListenAndServe(addr, mux); err != nil {
+				logger.Error("error serving metrics", zap.Error(err))
+			}
+		}()
+	}
+
 	logger.Info("running scheduler")
 
 	shutdown := make(chan os.Signal, 1)
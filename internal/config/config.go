@@ -24,21 +24,50 @@ func Default() Config {
 		HttpPort: "1234",
 		InMemory: false,
 		Monitoring: monitoring.Config{
-			RequestTimeout:  10 * time.Second,
-			NumberOfWorkers: runtime.NumCPU(),
+			RequestTimeout:       10 * time.Second,
+			NumberOfWorkers:      runtime.NumCPU(),
+			MinuteStatsRetention: 24 * time.Hour,
+			HourStatsRetention:   30 * 24 * time.Hour,
+			AlertPolicy: monitoring.AlertPolicy{
+				Cooldown:           10 * time.Minute,
+				SuppressDuplicates: true,
+				FlapWindow:         10 * time.Minute,
+				FlapThreshold:      6,
+			},
+			NotificationTimeout:     10 * time.Second,
+			NotificationWorkers:     2,
+			NotificationMaxAttempts: 8,
+			NotificationBackoff:     30 * time.Second,
+			PublicUrl:               "http://127.0.0.1:1234",
+			CatchUpWindow:           time.Minute,
+			Jitter:                  1,
+			HostLimit:               monitoring.Limit{Concurrency: 4, Rate: 5, Burst: 10},
+			MaxWorkers:              4 * runtime.NumCPU(),
+			ScaleUpWait:             2 * time.Second,
+			WorkerIdleTimeout:       time.Minute,
+			ProbeSharingWindow:      2 * time.Second,
 		},
 		Auth: auth.Config{
 			SigningKey:  "veryBadSecret",
 			ExpireAfter: 15 * time.Minute,
 		},
 		Database: db.Config{
-			URI:                "mongodb://127.0.0.1:27017",
-			DbName:             "httpm",
-			UserCollection:     "user",
-			UrlCollection:      "url",
-			AlertCollection:    "alert",
-			UrlEventCollection: "url_event",
-			ConnectionTimeout:  2 * time.Second,
+			URI:                        "mongodb://127.0.0.1:27017",
+			DbName:                     "httpm",
+			UserCollection:             "user",
+			UrlCollection:              "url",
+			AlertCollection:            "alert",
+			UrlEventCollection:         "url_event",
+			StatCollection:             "stat",
+			IncidentCollection:         "incident",
+			MaintenanceCollection:      "maintenance",
+			SilenceCollection:          "silence",
+			ChannelCollection:          "channel",
+			EscalationPolicyCollection: "escalation_policy",
+			EscalationCollection:       "escalation",
+			RoutingRuleCollection:      "routing_rule",
+			NotificationJobCollection:  "notification_job",
+			ConnectionTimeout:          2 * time.Second,
 		},
 	}
 }
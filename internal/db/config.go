@@ -3,11 +3,20 @@ package db
 import "time"
 
 type Config struct {
-	URI                string        `config:"uri"`
-	DbName             string        `config:"db_name"`
-	UserCollection     string        `config:"user_collection"`
-	UrlCollection      string        `config:"url_collection"`
-	AlertCollection    string        `config:"alert_collection"`
-	UrlEventCollection string        `config:"url_event_collection"`
-	ConnectionTimeout  time.Duration `config:"connection_timeout"`
+	URI                        string        `config:"uri"`
+	DbName                     string        `config:"db_name"`
+	UserCollection             string        `config:"user_collection"`
+	UrlCollection              string        `config:"url_collection"`
+	AlertCollection            string        `config:"alert_collection"`
+	UrlEventCollection         string        `config:"url_event_collection"`
+	StatCollection             string        `config:"stat_collection"`
+	IncidentCollection         string        `config:"incident_collection"`
+	MaintenanceCollection      string        `config:"maintenance_collection"`
+	SilenceCollection          string        `config:"silence_collection"`
+	ChannelCollection          string        `config:"channel_collection"`
+	EscalationPolicyCollection string        `config:"escalation_policy_collection"`
+	EscalationCollection       string        `config:"escalation_collection"`
+	RoutingRuleCollection      string        `config:"routing_rule_collection"`
+	NotificationJobCollection  string        `config:"notification_job_collection"`
+	ConnectionTimeout          time.Duration `config:"connection_timeout"`
 }
@@ -1,24 +1,236 @@
 package model
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+type AlertType string
+
+const (
+	AlertTypeDown     AlertType = "down"
+	AlertTypeFlapping AlertType = "flapping"
+	// AlertTypeRecovered is only notified when an alerted url recovers, it is not stored
+	AlertTypeRecovered AlertType = "recovered"
+	AlertTypeLatency   AlertType = "latency"
+	// AlertTypeAcknowledged and AlertTypeResolved are only notified to the integrations that track incidents,
+	// when a user acknowledges or resolves an alert. they are not stored
+	AlertTypeAcknowledged AlertType = "acknowledged"
+	AlertTypeResolved     AlertType = "resolved"
+)
+
+// AlertTypes lists the types that notifications can be routed by
+var AlertTypes = []AlertType{AlertTypeDown, AlertTypeFlapping, AlertTypeRecovered, AlertTypeLatency}
+
+// FollowUpAlertTypes are the types of notifications that follow up on an alert that was notified before
+var FollowUpAlertTypes = []AlertType{AlertTypeRecovered, AlertTypeAcknowledged, AlertTypeResolved}
+
+// Triggers reports whether notifications of the type raise an alert, rather than follow up on one
+func (t AlertType) Triggers() bool {
+	for _, f := range FollowUpAlertTypes {
+		if t == f {
+			return false
+		}
+	}
+	return true
+}
+
+type AlertSeverity string
+
+const (
+	AlertSeverityCritical AlertSeverity = "critical"
+	AlertSeverityWarning  AlertSeverity = "warning"
+	AlertSeverityInfo     AlertSeverity = "info"
+)
+
+var AlertSeverities = []AlertSeverity{AlertSeverityCritical, AlertSeverityWarning, AlertSeverityInfo}
+
+// SeverityOf returns the severity of the alerts of a type
+func SeverityOf(typ AlertType) AlertSeverity {
+	switch typ {
+	case AlertTypeDown:
+		return AlertSeverityCritical
+	case AlertTypeRecovered, AlertTypeAcknowledged, AlertTypeResolved:
+		return AlertSeverityInfo
+	default:
+		return AlertSeverityWarning
+	}
+}
+
+type AlertState string
+
+const (
+	AlertStateOpen         AlertState = "open"
+	AlertStateAcknowledged AlertState = "acknowledged"
+	AlertStateResolved     AlertState = "resolved"
+)
+
 type Alert struct {
-	Id       ID        `json:"-" bson:"_id"`
-	UserId   ID        `json:"-" bson:"user_id"`
-	UrlId    ID        `json:"url_id" bson:"url_id"`
-	Url      string    `json:"url" bson:"url"`
-	IssuedAt time.Time `json:"issued_at" bson:"issued_at"`
+	Id             ID            `json:"id" bson:"_id"`
+	UserId         ID            `json:"-" bson:"user_id"`
+	UrlId          ID            `json:"url_id" bson:"url_id"`
+	Url            string        `json:"url" bson:"url"`
+	IssuedAt       time.Time     `json:"issued_at" bson:"issued_at"`
+	IncidentId     ID            `json:"incident_id,omitempty" bson:"incident_id,omitempty"`
+	Type           AlertType     `json:"type" bson:"type"`
+	Severity       AlertSeverity `json:"severity" bson:"severity"`
+	State          AlertState    `json:"state" bson:"state"`
+	AcknowledgedAt *time.Time    `json:"acknowledged_at,omitempty" bson:"acknowledged_at,omitempty"`
+	AcknowledgedBy ID            `json:"acknowledged_by,omitempty" bson:"acknowledged_by,omitempty"`
+	ResolvedAt     *time.Time    `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+	ResolvedBy     ID            `json:"resolved_by,omitempty" bson:"resolved_by,omitempty"`
+	Notes          []AlertNote   `json:"notes,omitempty" bson:"notes,omitempty"`
+	// SilencedBy is the silence that muted the alert. silenced alerts are not notified
+	SilencedBy ID `json:"silenced_by,omitempty" bson:"silenced_by,omitempty"`
+	// Tags are the tags of the url when its incident was first alerted, so every alert of the incident has the same tags
+	Tags []string `json:"tags,omitempty" bson:"tags,omitempty"`
+}
+
+type AlertNote struct {
+	Text      string    `json:"text" bson:"text"`
+	CreatedBy ID        `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// AlertStateChange is an audited change of the alert state
+type AlertStateChange struct {
+	State     AlertState
+	ChangedBy ID
+	ChangedAt time.Time
 }
 
 func (a *Alert) NoId() bson.M {
+	// notes are pushed to an array, so they can not be stored as null
+	notes := a.Notes
+	if notes == nil {
+		notes = make([]AlertNote, 0)
+	}
+
 	return bson.M{
-		"user_id":   a.UserId,
-		"url_id":    a.UrlId,
-		"url":       a.Url,
-		"issued_at": a.IssuedAt,
+		"user_id":     a.UserId,
+		"url_id":      a.UrlId,
+		"url":         a.Url,
+		"issued_at":   a.IssuedAt,
+		"incident_id": a.IncidentId,
+		"type":        a.Type,
+		"severity":    a.Severity,
+		"state":       a.State,
+		"notes":       notes,
+		"silenced_by": a.SilencedBy,
+		"tags":        a.Tags,
+	}
+}
+
+func (a *Alert) Silenced() bool {
+	return a.SilencedBy != ""
+}
+
+// CurrentType returns the type of the alert. alerts stored before alert types were added are down alerts
+func (a *Alert) CurrentType() AlertType {
+	if a.Type == "" {
+		return AlertTypeDown
+	}
+	return a.Type
+}
+
+// CurrentSeverity returns the severity of the alert. alerts stored before severities were added have the severity of their type
+func (a *Alert) CurrentSeverity() AlertSeverity {
+	if a.Severity == "" {
+		return SeverityOf(a.CurrentType())
+	}
+	return a.Severity
+}
+
+// CurrentState returns the state of the alert. alerts stored before alert states were added are open
+func (a *Alert) CurrentState() AlertState {
+	if a.State == "" {
+		return AlertStateOpen
+	}
+	return a.State
+}
+
+// CanChangeTo reports whether the alert can move to state. resolved alerts can not be acknowledged
+func (a *Alert) CanChangeTo(state AlertState) bool {
+	for _, s := range StatesBefore(state) {
+		if a.CurrentState() == s {
+			return true
+		}
+	}
+	return false
+}
+
+// StatesBefore returns the states that an alert can move to state from
+func StatesBefore(state AlertState) []AlertState {
+	switch state {
+	case AlertStateAcknowledged:
+		return []AlertState{AlertStateOpen}
+	case AlertStateResolved:
+		return []AlertState{AlertStateOpen, AlertStateAcknowledged}
+	default:
+		return nil
+	}
+}
+
+// Apply sets the state of the alert and its audit fields
+func (a *Alert) Apply(change AlertStateChange) {
+	a.State = change.State
+	switch change.State {
+	case AlertStateAcknowledged:
+		a.AcknowledgedAt = &change.ChangedAt
+		a.AcknowledgedBy = change.ChangedBy
+	case AlertStateResolved:
+		a.ResolvedAt = &change.ChangedAt
+		a.ResolvedBy = change.ChangedBy
 	}
 }
+
+// AlertPage is a page of alerts sorted by IssuedAt, the latest first
+type AlertPage struct {
+	Alerts     []*Alert `json:"alerts"`
+	NextCursor string   `json:"next_cursor,omitempty" description:"cursor of the next page, empty if this is the last page"`
+}
+
+// AlertCursor points to the last alert of a page. the next page starts after it
+type AlertCursor struct {
+	IssuedAt time.Time
+	Id       ID
+}
+
+func CursorOf(a *Alert) AlertCursor {
+	return AlertCursor{IssuedAt: a.IssuedAt, Id: a.Id}
+}
+
+func (c AlertCursor) String() string {
+	raw := fmt.Sprintf("%d:%s", c.IssuedAt.UnixNano(), c.Id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func ParseAlertCursor(s string) (AlertCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return AlertCursor{}, errors.New("invalid cursor")
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return AlertCursor{}, errors.New("invalid cursor")
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return AlertCursor{}, errors.New("invalid cursor")
+	}
+
+	parsedId, err := ParseId(id)
+	if err != nil {
+		return AlertCursor{}, errors.New("invalid cursor")
+	}
+
+	return AlertCursor{IssuedAt: time.Unix(0, n).UTC(), Id: parsedId}, nil
+}
@@ -0,0 +1,64 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson"
+
+type ChannelType string
+
+const (
+	// ChannelTypeWebhook posts the notification as json to Url
+	ChannelTypeWebhook ChannelType = "webhook"
+	// ChannelTypeSlack posts the notification as blocks to a slack incoming webhook
+	ChannelTypeSlack ChannelType = "slack"
+	// ChannelTypeDiscord posts the notification as an embed to a discord webhook
+	ChannelTypeDiscord ChannelType = "discord"
+	// ChannelTypeMattermost posts the notification as an attachment to a mattermost incoming webhook
+	ChannelTypeMattermost ChannelType = "mattermost"
+	// ChannelTypeTeams posts the notification as an adaptive card to a microsoft teams incoming webhook
+	ChannelTypeTeams ChannelType = "teams"
+	// ChannelTypePagerDuty sends trigger and resolve events to the pagerduty events v2 api at Url
+	ChannelTypePagerDuty ChannelType = "pagerduty"
+	// ChannelTypeAlertmanager posts the notification as alerts to the /api/v2/alerts endpoint of the alertmanager at Url
+	ChannelTypeAlertmanager ChannelType = "alertmanager"
+)
+
+// ChannelTypes lists all supported channel types
+var ChannelTypes = []ChannelType{ChannelTypeWebhook, ChannelTypeSlack, ChannelTypeDiscord, ChannelTypeMattermost, ChannelTypeTeams, ChannelTypePagerDuty, ChannelTypeAlertmanager}
+
+// Channel is a destination of notifications
+type Channel struct {
+	Id     ID          `json:"id" bson:"_id"`
+	UserId ID          `json:"-" bson:"user_id"`
+	Name   string      `json:"name" bson:"name"`
+	Type   ChannelType `json:"type" bson:"type"`
+	Url    string      `json:"url" bson:"url"`
+	// RoutingKey is the integration key of the pagerduty service, only pagerduty channels have it
+	RoutingKey string `json:"routing_key,omitempty" bson:"routing_key,omitempty"`
+	// Template overrides the default message template of the channel type
+	Template *Template `json:"template,omitempty" bson:"template,omitempty"`
+}
+
+type TemplateFormat string
+
+const (
+	// TemplateFormatText templates are executed with text/template
+	TemplateFormatText TemplateFormat = "text"
+	// TemplateFormatHtml templates are executed with html/template, so values are escaped
+	TemplateFormatHtml TemplateFormat = "html"
+)
+
+// Template is the content of the messages sent to a channel
+type Template struct {
+	Format TemplateFormat `json:"format" bson:"format" enum:"text,html"`
+	Body   string         `json:"body" bson:"body"`
+}
+
+func (c *Channel) NoId() bson.M {
+	return bson.M{
+		"user_id":     c.UserId,
+		"name":        c.Name,
+		"type":        c.Type,
+		"url":         c.Url,
+		"routing_key": c.RoutingKey,
+		"template":    c.Template,
+	}
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// EscalationPolicy notifies the channels of its steps in order, until the alert is acknowledged or the url recovers
+type EscalationPolicy struct {
+	Id     ID               `json:"id" bson:"_id"`
+	UserId ID               `json:"-" bson:"user_id"`
+	Name   string           `json:"name" bson:"name"`
+	Steps  []EscalationStep `json:"steps" bson:"steps"`
+}
+
+// EscalationStep notifies its channels when After has passed since the alert was issued
+type EscalationStep struct {
+	After      Interval `json:"after" bson:"after" type:"string" example:"10m"`
+	ChannelIds []ID     `json:"channel_ids" bson:"channel_ids"`
+}
+
+func (p *EscalationPolicy) NoId() bson.M {
+	return bson.M{
+		"user_id": p.UserId,
+		"name":    p.Name,
+		"steps":   p.Steps,
+	}
+}
+
+type EscalationState string
+
+const (
+	EscalationStateActive EscalationState = "active"
+	// EscalationStateStopped means the alert was acknowledged or resolved, or the url recovered
+	EscalationStateStopped EscalationState = "stopped"
+	// EscalationStateCompleted means all steps were executed
+	EscalationStateCompleted EscalationState = "completed"
+)
+
+// Escalation is the progress of an escalation policy for an alert. Step is the next step to execute at NextAt
+type Escalation struct {
+	Id       ID              `json:"id" bson:"_id"`
+	UserId   ID              `json:"-" bson:"user_id"`
+	AlertId  ID              `json:"alert_id" bson:"alert_id"`
+	UrlId    ID              `json:"url_id" bson:"url_id"`
+	PolicyId ID              `json:"policy_id" bson:"policy_id"`
+	Step     int             `json:"step" bson:"step"`
+	NextAt   time.Time       `json:"next_at" bson:"next_at"`
+	State    EscalationState `json:"state" bson:"state"`
+}
+
+func (e *Escalation) NoId() bson.M {
+	return bson.M{
+		"user_id":   e.UserId,
+		"alert_id":  e.AlertId,
+		"url_id":    e.UrlId,
+		"policy_id": e.PolicyId,
+		"step":      e.Step,
+		"next_at":   e.NextAt,
+		"state":     e.State,
+	}
+}
+
+// Advance moves the escalation to the step after the executed one, or completes it
+func (e *Escalation) Advance(policy *EscalationPolicy, issuedAt time.Time) {
+	e.Step++
+	if e.Step >= len(policy.Steps) {
+		e.State = EscalationStateCompleted
+		return
+	}
+
+	e.NextAt = issuedAt.Add(policy.Steps[e.Step].After.Duration)
+}
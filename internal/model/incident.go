@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type IncidentState string
+
+const (
+	IncidentStateOpen     IncidentState = "open"
+	IncidentStateResolved IncidentState = "resolved"
+)
+
+type IncidentEventType string
+
+const (
+	IncidentEventOpened      IncidentEventType = "opened"
+	IncidentEventCheckFailed IncidentEventType = "check_failed"
+	IncidentEventAlert       IncidentEventType = "alert"
+	IncidentEventResolved    IncidentEventType = "resolved"
+)
+
+// Incident groups the failed checks and alerts of a url from the moment it goes down until it recovers
+type Incident struct {
+	Id           ID              `json:"id" bson:"_id"`
+	UserId       ID              `json:"-" bson:"user_id"`
+	UrlId        ID              `json:"url_id" bson:"url_id"`
+	Url          string          `json:"url" bson:"url"`
+	State        IncidentState   `json:"state" bson:"state"`
+	StartedAt    time.Time       `json:"started_at" bson:"started_at"`
+	ResolvedAt   *time.Time      `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+	FailedChecks int             `json:"failed_checks" bson:"failed_checks"`
+	AlertIds     []ID            `json:"alert_ids" bson:"alert_ids"`
+	Timeline     []IncidentEvent `json:"timeline,omitempty" bson:"timeline"`
+}
+
+// IncidentEvent is an entry of the incident timeline.
+// failed checks are only recorded when their status code differs from the previous one
+type IncidentEvent struct {
+	Type       IncidentEventType `json:"type" bson:"type"`
+	At         time.Time         `json:"at" bson:"at"`
+	StatusCode int               `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	AlertId    ID                `json:"alert_id,omitempty" bson:"alert_id,omitempty"`
+}
+
+func (i *Incident) NoId() bson.M {
+	return bson.M{
+		"user_id":       i.UserId,
+		"url_id":        i.UrlId,
+		"url":           i.Url,
+		"state":         i.State,
+		"started_at":    i.StartedAt,
+		"resolved_at":   i.ResolvedAt,
+		"failed_checks": i.FailedChecks,
+		"alert_ids":     i.AlertIds,
+		"timeline":      i.Timeline,
+	}
+}
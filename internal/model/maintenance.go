@@ -0,0 +1,150 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// maxOccurrences limits the number of occurrences of a recurring window computed for a time range
+const maxOccurrences = 10_000
+
+// ErrTooManyOccurrences is returned when a recurring window occurs more than maxOccurrences times in a time range
+var ErrTooManyOccurrences = fmt.Errorf("maintenance window occurs more than %d times in the time range", maxOccurrences)
+
+// Maintenance is a one-off or recurring time window in which no alerts are raised for a url, or the urls with a tag.
+// one-off windows use Start and End, recurring windows start on the times of the Cron expression and last for Duration
+type Maintenance struct {
+	Id         ID        `json:"id" bson:"_id"`
+	UserId     ID        `json:"-" bson:"user_id"`
+	UrlId      ID        `json:"url_id,omitempty" bson:"url_id,omitempty"`
+	Tag        string    `json:"tag,omitempty" bson:"tag,omitempty"`
+	Start      time.Time `json:"start" bson:"start,omitempty"`
+	End        time.Time `json:"end" bson:"end,omitempty"`
+	Cron       string    `json:"cron,omitempty" bson:"cron,omitempty"`
+	Duration   Interval  `json:"duration" bson:"duration"`
+	TimeZone   string    `json:"time_zone,omitempty" bson:"time_zone,omitempty"`
+	SkipChecks bool      `json:"skip_checks" bson:"skip_checks"`
+	Comment    string    `json:"comment,omitempty" bson:"comment,omitempty"`
+
+	// set by Compile
+	schedule cron.Schedule
+	loc      *time.Location
+}
+
+func (m *Maintenance) NoId() bson.M {
+	return bson.M{
+		"user_id":     m.UserId,
+		"url_id":      m.UrlId,
+		"tag":         m.Tag,
+		"start":       m.Start,
+		"end":         m.End,
+		"cron":        m.Cron,
+		"duration":    m.Duration,
+		"time_zone":   m.TimeZone,
+		"skip_checks": m.SkipChecks,
+		"comment":     m.Comment,
+	}
+}
+
+// ParseCron parses a standard 5 field cron expression
+func ParseCron(expr string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, errors.New("invalid cron expression")
+	}
+	return schedule, nil
+}
+
+func (m *Maintenance) IsRecurring() bool {
+	return m.Cron != ""
+}
+
+// AppliesTo reports whether the window is defined for the url
+func (m *Maintenance) AppliesTo(url *URL) bool {
+	if m.UserId != url.UserId {
+		return false
+	}
+
+	if m.UrlId != "" {
+		return m.UrlId == url.Id
+	}
+
+	return url.HasTag(m.Tag)
+}
+
+// Compile returns a copy of the window with its cron expression and time zone parsed, so they are not parsed on each check
+func (m *Maintenance) Compile() (*Maintenance, error) {
+	c := *m
+	c.loc = locationOf(m.TimeZone)
+	if m.IsRecurring() {
+		schedule, err := ParseCron(m.Cron)
+		if err != nil {
+			return nil, err
+		}
+		c.schedule = schedule
+	}
+
+	return &c, nil
+}
+
+// returns the parsed schedule and time zone of a recurring window, parsing them if the window is not compiled
+func (m *Maintenance) parsed() (cron.Schedule, *time.Location, error) {
+	if m.schedule != nil {
+		return m.schedule, m.loc, nil
+	}
+
+	c, err := m.Compile()
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.schedule, c.loc, nil
+}
+
+// ActiveAt reports whether t is in an occurrence of the window
+func (m *Maintenance) ActiveAt(t time.Time) bool {
+	if !m.IsRecurring() {
+		return !t.Before(m.Start) && t.Before(m.End)
+	}
+
+	schedule, loc, err := m.parsed()
+	if err != nil {
+		return false
+	}
+
+	// the window is active if it has started in (t - duration, t]
+	start := schedule.Next(t.In(loc).Add(-m.Duration.Duration))
+	return !start.After(t)
+}
+
+// Occurrences returns the occurrences of the window that overlap [from, to).
+// it returns ErrTooManyOccurrences instead of a part of them if there are more than maxOccurrences
+func (m *Maintenance) Occurrences(from, to time.Time) ([]TimeWindow, error) {
+	if !m.IsRecurring() {
+		w := TimeWindow{Start: m.Start, End: m.End}
+		if w.Overlap(TimeWindow{Start: from, End: to}) == 0 {
+			return nil, nil
+		}
+		return []TimeWindow{w}, nil
+	}
+
+	schedule, loc, err := m.parsed()
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := make([]TimeWindow, 0)
+	start := schedule.Next(from.In(loc).Add(-m.Duration.Duration))
+	for start.Before(to) {
+		if len(occurrences) == maxOccurrences {
+			return nil, ErrTooManyOccurrences
+		}
+		occurrences = append(occurrences, TimeWindow{Start: start, End: start.Add(m.Duration.Duration)})
+		start = schedule.Next(start)
+	}
+
+	return occurrences, nil
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CheckResult is the result of checking a url
+type CheckResult struct {
+	StatusCode int       `json:"status_code" bson:"status_code"`
+	Body       string    `json:"body" bson:"body" description:"response body, truncated. the error of the request if the status code is 0"`
+	CheckedAt  time.Time `json:"checked_at" bson:"checked_at"`
+}
+
+type NotificationJobStatus string
+
+const (
+	// NotificationJobStatusPending jobs are sent at NextAttemptAt
+	NotificationJobStatusPending   NotificationJobStatus = "pending"
+	NotificationJobStatusSucceeded NotificationJobStatus = "succeeded"
+	// NotificationJobStatusDead jobs failed too many times. they are only sent again if they are re-driven
+	NotificationJobStatusDead NotificationJobStatus = "dead"
+)
+
+var NotificationJobStatuses = []NotificationJobStatus{NotificationJobStatusPending, NotificationJobStatusSucceeded, NotificationJobStatusDead}
+
+// NotificationJob is a message of an alert to be sent to a channel. jobs are stored, so they are sent after a restart
+type NotificationJob struct {
+	Id        ID            `json:"id" bson:"_id"`
+	UserId    ID            `json:"-" bson:"user_id"`
+	ChannelId ID            `json:"channel_id" bson:"channel_id"`
+	AlertId   ID            `json:"alert_id" bson:"alert_id"`
+	UrlId     ID            `json:"url_id" bson:"url_id"`
+	Type      AlertType     `json:"type" bson:"type"`
+	Severity  AlertSeverity `json:"severity" bson:"severity"`
+	// Result is the latest check of the url when the job was created
+	Result        *CheckResult          `json:"result,omitempty" bson:"result,omitempty"`
+	Status        NotificationJobStatus `json:"status" bson:"status"`
+	Attempts      int                   `json:"attempts" bson:"attempts"`
+	NextAttemptAt time.Time             `json:"next_attempt_at" bson:"next_attempt_at"`
+	LastError     string                `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt     time.Time             `json:"created_at" bson:"created_at"`
+	CompletedAt   *time.Time            `json:"completed_at,omitempty" bson:"completed_at,omitempty" description:"when the job succeeded or died"`
+}
+
+// NewNotificationJob returns a pending job of the notification of the alert to the channel, due at now
+func NewNotificationJob(channelId ID, alert *Alert, typ AlertType, severity AlertSeverity, result *CheckResult, now time.Time) *NotificationJob {
+	return &NotificationJob{
+		UserId:        alert.UserId,
+		ChannelId:     channelId,
+		AlertId:       alert.Id,
+		UrlId:         alert.UrlId,
+		Type:          typ,
+		Severity:      severity,
+		Result:        result,
+		Status:        NotificationJobStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+}
+
+func (j *NotificationJob) NoId() bson.M {
+	return bson.M{
+		"user_id":         j.UserId,
+		"channel_id":      j.ChannelId,
+		"alert_id":        j.AlertId,
+		"url_id":          j.UrlId,
+		"type":            j.Type,
+		"severity":        j.Severity,
+		"result":          j.Result,
+		"status":          j.Status,
+		"attempts":        j.Attempts,
+		"next_attempt_at": j.NextAttemptAt,
+		"last_error":      j.LastError,
+		"created_at":      j.CreatedAt,
+		"completed_at":    j.CompletedAt,
+	}
+}
+
+func (j *NotificationJob) Succeed(at time.Time) {
+	j.Status = NotificationJobStatusSucceeded
+	j.LastError = ""
+	j.CompletedAt = &at
+}
+
+// Fail records the failed attempt. the job is retried at retryAt, unless it has been attempted maxAttempts times
+func (j *NotificationJob) Fail(reason string, at time.Time, retryAt time.Time, maxAttempts int) {
+	j.LastError = reason
+	if j.Attempts >= maxAttempts {
+		j.Status = NotificationJobStatusDead
+		j.CompletedAt = &at
+		return
+	}
+	j.NextAttemptAt = retryAt
+}
+
+// Kill dead-letters the job without retrying it, because it can never succeed
+func (j *NotificationJob) Kill(reason string, at time.Time) {
+	j.LastError = reason
+	j.Status = NotificationJobStatusDead
+	j.CompletedAt = &at
+}
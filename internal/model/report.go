@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+type TimeWindow struct {
+	Start time.Time `json:"start" bson:"start"`
+	End   time.Time `json:"end" bson:"end"`
+}
+
+// Overlap returns the duration that w and other have in common
+func (w TimeWindow) Overlap(other TimeWindow) time.Duration {
+	start, end := w.Start, w.End
+	if other.Start.After(start) {
+		start = other.Start
+	}
+	if other.End.Before(end) {
+		end = other.End
+	}
+
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+type UptimeReport struct {
+	UrlId            ID         `json:"url_id"`
+	From             time.Time  `json:"from"`
+	To               time.Time  `json:"to"`
+	Resolution       Resolution `json:"resolution" description:"resolution of the stats the report is computed from"`
+	UptimePercentage float64    `json:"uptime_percentage"`
+	Monitored        Interval   `json:"monitored" description:"time the url was monitored in the window, excluding maintenance unless it was included"`
+	Downtime         Interval   `json:"downtime"`
+	Incidents        int        `json:"incidents"`
+	MTTR             Interval   `json:"mttr" description:"mean time to recovery"`
+	MTBF             Interval   `json:"mtbf" description:"mean time between failures"`
+	SuccessCount     int        `json:"success_count"`
+	FailureCount     int        `json:"failure_count"`
+	AlertCount       int        `json:"alert_count"`
+}
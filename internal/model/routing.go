@@ -0,0 +1,113 @@
+package model
+
+import (
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// RoutingRule selects the channels notified of the alerts it matches. empty matchers match everything.
+// rules are evaluated by Order, and no rule after a matching rule with Stop is evaluated
+type RoutingRule struct {
+	Id         ID              `json:"id" bson:"_id"`
+	UserId     ID              `json:"-" bson:"user_id"`
+	Name       string          `json:"name" bson:"name"`
+	Order      int             `json:"order" bson:"order"`
+	UrlIds     []ID            `json:"url_ids,omitempty" bson:"url_ids,omitempty"`
+	Tags       []string        `json:"tags,omitempty" bson:"tags,omitempty"`
+	Types      []AlertType     `json:"types,omitempty" bson:"types,omitempty"`
+	Severities []AlertSeverity `json:"severities,omitempty" bson:"severities,omitempty"`
+	ChannelIds []ID            `json:"channel_ids" bson:"channel_ids"`
+	Stop       bool            `json:"stop" bson:"stop"`
+}
+
+func (r *RoutingRule) NoId() bson.M {
+	return bson.M{
+		"user_id":     r.UserId,
+		"name":        r.Name,
+		"order":       r.Order,
+		"url_ids":     r.UrlIds,
+		"tags":        r.Tags,
+		"types":       r.Types,
+		"severities":  r.Severities,
+		"channel_ids": r.ChannelIds,
+		"stop":        r.Stop,
+	}
+}
+
+// Matches reports whether an alert of the url, with the type and severity, is routed by the rule.
+// a url matches if it has any of the tags
+func (r *RoutingRule) Matches(url *URL, typ AlertType, severity AlertSeverity) bool {
+	if url.UserId != r.UserId {
+		return false
+	}
+
+	if len(r.UrlIds) > 0 && !contains(r.UrlIds, url.Id) {
+		return false
+	}
+
+	if len(r.Tags) > 0 {
+		tagged := false
+		for _, tag := range r.Tags {
+			tagged = tagged || url.HasTag(tag)
+		}
+		if !tagged {
+			return false
+		}
+	}
+
+	if len(r.Types) > 0 && !contains(r.Types, typ) {
+		return false
+	}
+
+	if len(r.Severities) > 0 && !contains(r.Severities, severity) {
+		return false
+	}
+
+	return true
+}
+
+// Route returns the rules matching an alert and the channels they select, without duplicates
+func Route(rules []*RoutingRule, url *URL, typ AlertType, severity AlertSeverity) ([]*RoutingRule, []ID) {
+	sorted := make([]*RoutingRule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+
+	matched := make([]*RoutingRule, 0)
+	channelIds := make([]ID, 0)
+	for _, rule := range sorted {
+		if !rule.Matches(url, typ, severity) {
+			continue
+		}
+
+		matched = append(matched, rule)
+		for _, id := range rule.ChannelIds {
+			if !contains(channelIds, id) {
+				channelIds = append(channelIds, id)
+			}
+		}
+
+		if rule.Stop {
+			break
+		}
+	}
+
+	return matched, channelIds
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RoutingDryRun shows how a hypothetical alert would be routed
+type RoutingDryRun struct {
+	Rules    []*RoutingRule `json:"rules" description:"matching rules in evaluation order"`
+	Channels []*Channel     `json:"channels" description:"channels that would be notified"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule calls a url on the times of cron expressions, evaluated in a time zone, instead of every interval.
+// the url is called on the times of all the expressions, e.g. "*/5 9-17 * * 1-5" and "0 * * * *"
+// check it every 5 minutes in business hours and hourly otherwise
+type Schedule struct {
+	Cron     []string `json:"cron" bson:"cron"`
+	TimeZone string   `json:"time_zone,omitempty" bson:"time_zone,omitempty"`
+}
+
+// Parse returns the union of the expressions of the schedule, in its time zone
+func (s *Schedule) Parse() (cron.Schedule, error) {
+	u := union{location: locationOf(s.TimeZone)}
+	for _, expr := range s.Cron {
+		schedule, err := ParseCron(expr)
+		if err != nil {
+			return nil, err
+		}
+		u.schedules = append(u.schedules, schedule)
+	}
+
+	return u, nil
+}
+
+// Runs returns the next n call times of the schedule after t. it returns fewer if the schedule stops
+func (s *Schedule) Runs(after time.Time, n int) ([]time.Time, error) {
+	schedule, err := s.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]time.Time, 0, n)
+	for t := schedule.Next(after); !t.IsZero() && len(runs) < n; t = schedule.Next(t) {
+		runs = append(runs, t)
+	}
+
+	return runs, nil
+}
+
+type union struct {
+	schedules []cron.Schedule
+	location  *time.Location
+}
+
+// Next returns the earliest next time of the schedules, or zero time if none of them has one
+func (u union) Next(t time.Time) time.Time {
+	var next time.Time
+	for _, s := range u.schedules {
+		n := s.Next(t.In(u.location))
+		if !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+
+	return next
+}
+
+// locationOf returns the time zone of name, falling back to UTC
+func locationOf(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type MatcherName string
+
+const (
+	MatcherUrlId MatcherName = "url_id"
+	MatcherUrl   MatcherName = "url"
+	MatcherTag   MatcherName = "tag"
+)
+
+// SilenceMatcher matches the urls whose field Name equals Value
+type SilenceMatcher struct {
+	Name  MatcherName `json:"name" bson:"name" enum:"url_id,url,tag"`
+	Value string      `json:"value" bson:"value"`
+}
+
+func (m SilenceMatcher) Matches(url *URL) bool {
+	switch m.Name {
+	case MatcherUrlId:
+		return string(url.Id) == m.Value
+	case MatcherUrl:
+		return url.Url == m.Value
+	case MatcherTag:
+		return url.HasTag(m.Value)
+	default:
+		return false
+	}
+}
+
+// Silence mutes the alerts of the urls matched by all of its matchers in [StartsAt, EndsAt).
+// silenced alerts are stored, but not notified
+type Silence struct {
+	Id        ID               `json:"id" bson:"_id"`
+	UserId    ID               `json:"-" bson:"user_id"`
+	Matchers  []SilenceMatcher `json:"matchers" bson:"matchers"`
+	StartsAt  time.Time        `json:"starts_at" bson:"starts_at"`
+	EndsAt    time.Time        `json:"ends_at" bson:"ends_at"`
+	CreatedBy string           `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time        `json:"created_at" bson:"created_at"`
+	Comment   string           `json:"comment,omitempty" bson:"comment,omitempty"`
+}
+
+func (s *Silence) NoId() bson.M {
+	return bson.M{
+		"user_id":    s.UserId,
+		"matchers":   s.Matchers,
+		"starts_at":  s.StartsAt,
+		"ends_at":    s.EndsAt,
+		"created_by": s.CreatedBy,
+		"created_at": s.CreatedAt,
+		"comment":    s.Comment,
+	}
+}
+
+// Matches reports whether all matchers of the silence match the url
+func (s *Silence) Matches(url *URL) bool {
+	if s.UserId != url.UserId || len(s.Matchers) == 0 {
+		return false
+	}
+
+	for _, m := range s.Matchers {
+		if !m.Matches(url) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Silence) ActiveAt(t time.Time) bool {
+	return !t.Before(s.StartsAt) && t.Before(s.EndsAt)
+}
+
+func (s *Silence) Expired(now time.Time) bool {
+	return !now.Before(s.EndsAt)
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+type Resolution string
+
+const (
+	ResolutionMinute Resolution = "minute"
+	ResolutionHour   Resolution = "hour"
+	ResolutionDay    Resolution = "day"
+)
+
+// Resolutions lists all supported resolutions from the finest to the coarsest
+var Resolutions = []Resolution{ResolutionMinute, ResolutionHour, ResolutionDay}
+
+func ParseResolution(s string) (Resolution, error) {
+	for _, r := range Resolutions {
+		if string(r) == s {
+			return r, nil
+		}
+	}
+
+	return "", errors.New("invalid resolution")
+}
+
+func (r Resolution) Duration() time.Duration {
+	switch r {
+	case ResolutionMinute:
+		return time.Minute
+	case ResolutionHour:
+		return time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// Truncate returns the start of the bucket containing t. buckets are aligned in UTC
+func (r Resolution) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	switch r {
+	case ResolutionMinute:
+		return t.Truncate(time.Minute)
+	case ResolutionHour:
+		return t.Truncate(time.Hour)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Stat holds the check results of a url in the bucket [Start, Start + Resolution)
+type Stat struct {
+	Resolution   Resolution `json:"resolution" bson:"resolution"`
+	Start        time.Time  `json:"start" bson:"start"`
+	SuccessCount int        `json:"success_count" bson:"success_count"`
+	FailureCount int        `json:"failure_count" bson:"failure_count"`
+	ExpiresAt    time.Time  `json:"-" bson:"expires_at,omitempty"` // zero value means the bucket never expires
+}
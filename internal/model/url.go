@@ -14,23 +14,75 @@ type URL struct {
 	Url       string     `json:"url" bson:"url"`
 	Threshold int        `json:"threshold" bson:"threshold"`
 	Interval  Interval   `json:"interval" bson:"interval"`
+	Tags      []string   `json:"tags" bson:"tags"`
+	Paused    bool       `json:"paused" bson:"paused"`
 	DayStats  []*DayStat `json:"-" bson:"day_stats"`
+	// AlertPolicy overrides the default alerting policy of the monitor
+	AlertPolicy AlertPolicy `json:"alert_policy" bson:"alert_policy"`
+	// EscalationPolicyId is the policy used to notify the alerts of the url
+	EscalationPolicyId ID `json:"escalation_policy_id,omitempty" bson:"escalation_policy_id,omitempty"`
+	// LastCheckedAt is set by the monitor, so it can resume the schedule of the url after a restart
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty" bson:"last_checked_at,omitempty"`
+	// Schedule replaces Interval when it is set
+	Schedule *Schedule `json:"schedule,omitempty" bson:"schedule,omitempty"`
+	// FailingPolicy changes how often the url is checked while it is failing
+	FailingPolicy *FailingPolicy `json:"failing_policy,omitempty" bson:"failing_policy,omitempty"`
+}
+
+// AlertPolicy controls when alerts are raised for a url. nil fields use the defaults of the monitor
+type AlertPolicy struct {
+	// minimum time between two alerts of the url
+	Cooldown *Interval `json:"cooldown,omitempty" bson:"cooldown,omitempty"`
+	// raise at most one down alert per incident
+	SuppressDuplicates *bool `json:"suppress_duplicates,omitempty" bson:"suppress_duplicates,omitempty"`
+	// the url is flapping when its state changes at least FlapThreshold times in FlapWindow. zero threshold disables flap detection
+	FlapWindow    *Interval `json:"flap_window,omitempty" bson:"flap_window,omitempty"`
+	FlapThreshold *int      `json:"flap_threshold,omitempty" bson:"flap_threshold,omitempty"`
+}
+
+// FailingPolicy checks a failing url more often, so its failure is confirmed and its recovery is detected sooner.
+// a url that stays down can be slowed down again
+type FailingPolicy struct {
+	// time between checks from the first failure until the url recovers
+	Interval Interval `json:"interval" bson:"interval"`
+	// after the url is down for this long, the time between checks is multiplied by Backoff on each failure, up to
+	// MaxInterval. zero disables slowing down
+	SlowDownAfter Interval `json:"slow_down_after" bson:"slow_down_after"`
+	Backoff       float64  `json:"backoff" bson:"backoff"`
+	MaxInterval   Interval `json:"max_interval" bson:"max_interval"`
 }
 
 func (u *URL) NoId() bson.M {
 	return bson.M{
-		"user_id":   u.UserId,
-		"url":       u.Url,
-		"threshold": u.Threshold,
-		"interval":  u.Interval,
-		"day_stats": u.DayStats,
+		"user_id":              u.UserId,
+		"url":                  u.Url,
+		"threshold":            u.Threshold,
+		"interval":             u.Interval,
+		"tags":                 u.Tags,
+		"paused":               u.Paused,
+		"day_stats":            u.DayStats,
+		"alert_policy":         u.AlertPolicy,
+		"escalation_policy_id": u.EscalationPolicyId,
+		"schedule":             u.Schedule,
+		"failing_policy":       u.FailingPolicy,
 	}
 }
 
+func (u *URL) HasTag(tag string) bool {
+	for _, t := range u.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 type DayStat struct {
 	Date         Date `json:"date" bson:"date"`
 	SuccessCount int  `json:"success_count" bson:"success_count"`
 	FailureCount int  `json:"failure_count" bson:"failure_count"`
+	// Start is the start of the utc day, rendered in the requested time zone. it is not stored
+	Start time.Time `json:"start" bson:"-"`
 }
 
 type Date struct {
@@ -48,8 +100,9 @@ func ParseDate(s string) (Date, error) {
 	}, err
 }
 
-func Today() Date {
-	date := time.Now()
+// DateOf returns the date of t in UTC, so dates do not depend on the time zone of the host
+func DateOf(t time.Time) Date {
+	date := t.UTC()
 	return Date{
 		Day:   date.Day(),
 		Month: int(date.Month()),
@@ -57,6 +110,11 @@ func Today() Date {
 	}
 }
 
+// Start returns the beginning of the date in UTC
+func (d Date) Start() time.Time {
+	return time.Date(d.Year, time.Month(d.Month), d.Day, 0, 0, 0, 0, time.UTC)
+}
+
 type Interval struct {
 	time.Duration
 }
@@ -6,11 +6,13 @@ type User struct {
 	Id       ID     `json:"id" bson:"_id"`
 	Username string `json:"username" bson:"username"`
 	Password string `json:"password" bson:"password"`
+	TimeZone string `json:"time_zone" bson:"time_zone"` // IANA time zone name, used as the default for stats queries
 }
 
 func (u *User) NoId() bson.M {
 	return bson.M{
-		"username": u.Username,
-		"password": u.Password,
+		"username":  u.Username,
+		"password":  u.Password,
+		"time_zone": u.TimeZone,
 	}
 }
@@ -0,0 +1,491 @@
+package monitoring
+
+import (
+	"container/heap"
+	"math"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/clock"
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"go.uber.org/zap"
+)
+
+// passes the due tasks from 'schedule' to the workers. the tasks of a host or a user that reached its limits
+// wait in pending until the limits allow them, so a burst of checks is spread instead of dropped.
+// the users share the workers by their weights, using start-time fair queueing: each user has a virtual time that
+// advances by 1/weight for each of its checks, and the user with the earliest virtual time goes first.
+// a check of a target that is pending or in flight for the same user within the sharing window joins its probe,
+// instead of taking a worker and the limits for itself
+type balancer struct {
+	logger    *zap.Logger
+	clock     clock.Clock
+	hostLimit Limit
+	userLimit Limit
+	weights   map[string]float64
+	// grown when a task waits for a worker. nil when the workers are not elastic
+	pool *workerPool
+	// the checks of a target that are due within sharing of each other share a probe. zero disables sharing
+	sharing time.Duration
+
+	// the running counts are released by the workers, and the queues are read by the metrics
+	mutex    sync.Mutex
+	hosts    map[string]*usage
+	users    map[model.ID]*usage
+	queues   map[model.ID]*userQueue
+	released chan struct{}
+	// the checks that joined the probe of another check
+	shared int64
+	// the users whose limits may allow their earliest ready task, by their virtual times
+	ready userHeap
+	// the hosts and the users that reached their limits, with their pending tasks. they are checked again before a
+	// task is picked, so the tasks that wait for them are not read
+	heldHosts map[string][]*hostTasks
+	heldUsers map[model.ID]*userQueue
+
+	// only accessed by 'balance'
+	queued map[model.ID]bool
+	seq    uint64
+	vnow   float64
+	grown  time.Time
+	// the pending and the started probes by their keys
+	probes   map[string]*pendingTask
+	inflight map[string]*probe
+	// the started probes in the order they started, so the ones older than the sharing window are removed
+	started []*probe
+}
+
+// the running checks and the rate of a host or a user
+type usage struct {
+	running int
+	bucket  *bucket
+}
+
+func newBalancer(logger *zap.Logger, cfg Config, clk clock.Clock) *balancer {
+	return &balancer{
+		logger:    logger,
+		clock:     clk,
+		hostLimit: cfg.HostLimit,
+		userLimit: cfg.UserLimit,
+		weights:   cfg.UserWeights,
+		sharing:   cfg.ProbeSharingWindow,
+		hosts:     make(map[string]*usage),
+		users:     make(map[model.ID]*usage),
+		queues:    make(map[model.ID]*userQueue),
+		heldHosts: make(map[string][]*hostTasks),
+		heldUsers: make(map[model.ID]*userQueue),
+		released:  make(chan struct{}, 1),
+		queued:    make(map[model.ID]bool),
+		probes:    make(map[string]*pendingTask),
+		inflight:  make(map[string]*probe),
+	}
+}
+
+// reads from "in" and writes to "tasks". it closes "tasks" when "in" is closed, and the pending tasks are dropped
+func (b *balancer) run(in <-chan *Task, tasks chan<- *Task) {
+	timer := b.clock.NewTimer(0)
+	defer timer.Stop()
+	clock.StopTimer(timer)
+
+	for {
+		now := b.clock.Now()
+		next, wait := b.next(now)
+
+		// a nil channel is never ready, so "tasks" is only written when a task is allowed
+		var out chan<- *Task
+		var task *Task
+		if next != nil {
+			out, task = tasks, next.task
+
+			select {
+			case out <- task:
+				b.start(next, b.clock.Now())
+				continue
+			default:
+			}
+
+			// no worker is idle. the pool grows if the task is still waiting scaleUpWait after it was due,
+			// and after the pool last grew, so a new worker has time to start
+			if b.pool != nil && b.pool.elastic() {
+				since := task.due
+				if b.grown.After(since) {
+					since = b.grown
+				}
+				wait = since.Add(b.pool.scaleUpWait).Sub(now)
+				if wait <= 0 {
+					wait = time.Nanosecond
+				}
+			}
+		}
+		if wait > 0 {
+			timer.Reset(wait)
+		}
+
+		select {
+		case t, ok := <-in:
+			if !ok {
+				b.logger.Info("dropping pending tasks", zap.Int("count", len(b.queued)))
+				close(tasks)
+				return
+			}
+			b.add(t)
+		case out <- task:
+			b.start(next, b.clock.Now())
+		case <-b.released:
+		case <-timer.C():
+			if next != nil && b.pool != nil && b.pool.grow() {
+				b.grown = b.clock.Now()
+				b.logger.Debug("a due task is waiting for a worker", zap.Any("url_id", next.task.UrlId))
+			}
+		}
+
+		clock.StopTimer(timer)
+	}
+}
+
+func (b *balancer) add(t *Task) {
+	// the previous check of the url is still waiting, so this one is not needed
+	if b.queued[t.UrlId] {
+		b.logger.Debug("url is already pending", zap.Any("url_id", t.UrlId))
+		return
+	}
+
+	if b.sharing > 0 && b.share(t) {
+		b.mutex.Lock()
+		b.shared++
+		b.mutex.Unlock()
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	q, ok := b.queues[t.UserId]
+	if !ok {
+		q = newUserQueue(t.UserId, b.weightOf(t.UserId))
+		b.queues[t.UserId] = q
+	}
+
+	host := hostOf(t.URL)
+	// set before the task is sent, so the worker reads it after
+	t.done = func() {
+		b.release(host, t.UserId)
+	}
+
+	b.seq++
+	b.queued[t.UrlId] = true
+	p := &pendingTask{task: t, host: host, seq: b.seq}
+	if h, isNew := q.push(p); isNew {
+		if _, held := b.heldHosts[host]; held {
+			b.heldHosts[host] = append(b.heldHosts[host], h)
+		} else {
+			heap.Push(&q.ready, h)
+		}
+	}
+	b.queue(q)
+
+	if b.sharing > 0 {
+		t.probe = newProbe(t)
+		b.probes[t.probe.key] = p
+	}
+}
+
+// share joins the task to the probe of the same target that is pending, or started, within the sharing window
+func (b *balancer) share(t *Task) bool {
+	key := probeKey(t)
+
+	if p, ok := b.probes[key]; ok && absDuration(t.due.Sub(p.task.due)) <= b.sharing {
+		p.task.probe.join(t)
+		b.queued[t.UrlId] = true
+		b.logger.Debug("url joined a pending probe", zap.Any("url_id", t.UrlId))
+		return true
+	}
+
+	if p, ok := b.inflight[key]; ok {
+		// the result of the probe is too old for the task, or already sent
+		if t.due.Sub(p.started) > b.sharing || !p.join(t) {
+			delete(b.inflight, key)
+			return false
+		}
+		b.logger.Debug("url joined a running probe", zap.Any("url_id", t.UrlId))
+
+		// the probe is of the same user, so its queue exists
+		b.mutex.Lock()
+		b.queues[t.UserId].lag.observe(b.clock.Now().Sub(t.due))
+		b.mutex.Unlock()
+		return true
+	}
+
+	return false
+}
+
+func (b *balancer) weightOf(userId model.ID) float64 {
+	if w, ok := b.weights[string(userId)]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// next returns the pending task that the limits allow at now, of the user with the earliest virtual time.
+// if there is none, it returns how long until a rate allows one. zero means a release or a new task is needed
+func (b *balancer) next(now time.Time) (*pendingTask, time.Duration) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var wait time.Duration
+	sooner := func(w time.Duration) {
+		if w > 0 && (wait == 0 || w < wait) {
+			wait = w
+		}
+	}
+
+	// the hosts and the users that reached their limits may allow their tasks again
+	for host, held := range b.heldHosts {
+		if w, ok := b.allowed(usageOf(b.hosts, host, b.hostLimit), b.hostLimit, now); !ok {
+			sooner(w)
+			continue
+		}
+		delete(b.heldHosts, host)
+		for _, h := range held {
+			heap.Push(&h.queue.ready, h)
+			b.queue(h.queue)
+		}
+	}
+	for userId, q := range b.heldUsers {
+		if w, ok := b.allowed(usageOf(b.users, userId, b.userLimit), b.userLimit, now); !ok {
+			sooner(w)
+			continue
+		}
+		delete(b.heldUsers, userId)
+		b.queue(q)
+	}
+
+	// a user or a host that reached its limits is set aside until they allow it again
+	for len(b.ready) > 0 {
+		q := b.ready[0]
+		if w, ok := b.allowed(usageOf(b.users, q.userId, b.userLimit), b.userLimit, now); !ok {
+			sooner(w)
+			heap.Pop(&b.ready)
+			b.heldUsers[q.userId] = q
+			continue
+		}
+
+		h := q.ready[0]
+		if w, ok := b.allowed(usageOf(b.hosts, h.host, b.hostLimit), b.hostLimit, now); !ok {
+			sooner(w)
+			heap.Pop(&q.ready)
+			b.heldHosts[h.host] = append(b.heldHosts[h.host], h)
+			b.queue(q)
+			continue
+		}
+
+		return h.tasks[0], 0
+	}
+
+	return nil, wait
+}
+
+// queue puts the user in the ready users if one of its hosts may allow a task, or moves it by its earliest task.
+// a user that was not ready starts at the virtual time of the balancer, so it does not catch up on its idle time
+func (b *balancer) queue(q *userQueue) {
+	if _, held := b.heldUsers[q.userId]; held {
+		return
+	}
+
+	switch {
+	case q.index >= 0 && len(q.ready) == 0:
+		heap.Remove(&b.ready, q.index)
+	case q.index >= 0:
+		heap.Fix(&b.ready, q.index)
+	case len(q.ready) > 0:
+		q.vtime = math.Max(q.vtime, b.vnow)
+		heap.Push(&b.ready, q)
+	}
+}
+
+// reports whether the limit of a host or a user allows a task at now, or how long until its rate allows one
+func (b *balancer) allowed(u *usage, limit Limit, now time.Time) (time.Duration, bool) {
+	if u.full(limit) {
+		return 0, false
+	}
+
+	if u.bucket == nil {
+		return 0, true
+	}
+	wait := u.bucket.wait(now)
+	return wait, wait == 0
+}
+
+// counts the task as running, advances the virtual time of its user, and removes it from pending
+func (b *balancer) start(p *pendingTask, now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, u := range []*usage{b.hosts[p.host], b.users[p.task.UserId]} {
+		u.running++
+		if u.bucket != nil {
+			u.bucket.take(now)
+		}
+	}
+
+	q := b.queues[p.task.UserId]
+	b.vnow = math.Max(q.vtime, b.vnow)
+	q.vtime = b.vnow + 1/q.weight
+	q.lag.observe(now.Sub(p.task.due))
+
+	q.remove(p)
+	b.queue(q)
+	delete(b.queued, p.task.UrlId)
+
+	if pr := p.task.probe; pr != nil {
+		if b.probes[pr.key] == p {
+			delete(b.probes, pr.key)
+		}
+		pr.started = now
+		b.inflight[pr.key] = pr
+		b.started = append(b.started, pr)
+		for _, t := range pr.joined() {
+			delete(b.queued, t.UrlId)
+			// the checks that joined the probe are done when it starts
+			if t != p.task {
+				q.lag.observe(now.Sub(t.due))
+			}
+		}
+	}
+
+	b.prune(now)
+}
+
+// removes the started probes that no task can join anymore, even if no task of their targets comes again
+func (b *balancer) prune(now time.Time) {
+	for len(b.started) > 0 && now.Sub(b.started[0].started) > b.sharing {
+		pr := b.started[0]
+		// the probe may be replaced by a newer one of its target
+		if b.inflight[pr.key] == pr {
+			delete(b.inflight, pr.key)
+		}
+		b.started[0] = nil
+		b.started = b.started[1:]
+	}
+}
+
+// metrics returns the schedule lag and the pending checks of each user
+func (b *balancer) metrics() map[model.ID]userMetrics {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	metrics := make(map[model.ID]userMetrics, len(b.queues))
+	for userId, q := range b.queues {
+		metrics[userId] = userMetrics{
+			Pending:         q.pending,
+			Checks:          q.lag.count,
+			LagSecondsTotal: q.lag.total.Seconds(),
+			LagSecondsMax:   q.lag.max.Seconds(),
+			LagSecondsLast:  q.lag.last.Seconds(),
+		}
+	}
+	return metrics
+}
+
+// sharedChecks returns how many checks joined the probe of another check, instead of sending a request
+func (b *balancer) sharedChecks() int64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.shared
+}
+
+// called by the workers when a task is done
+func (b *balancer) release(host string, userId model.ID) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.hosts[host].running--
+	b.users[userId].running--
+
+	select {
+	case b.released <- struct{}{}:
+	default:
+	}
+}
+
+// called while holding the lock
+func usageOf[K comparable](all map[K]*usage, key K, limit Limit) *usage {
+	u, ok := all[key]
+	if !ok {
+		u = &usage{}
+		if limit.Rate > 0 {
+			u.bucket = newBucket(limit.Rate, limit.Burst)
+		}
+		all[key] = u
+	}
+	return u
+}
+
+func (u *usage) full(limit Limit) bool {
+	return limit.Concurrency > 0 && u.running >= limit.Concurrency
+}
+
+// hostOf returns the host and port of the url, so different ports of a host are limited separately
+func hostOf(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return rawUrl
+	}
+	return u.Host
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// a token is available a little early, so rounding the refill does not leave it just short of one
+const tokenTolerance = 1e-9
+
+// a token bucket that allows rate tokens per second, and holds up to burst tokens
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	at     time.Time
+}
+
+func newBucket(rate float64, burst int) *bucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if b.at.IsZero() {
+		b.at = now
+		return
+	}
+
+	if now.After(b.at) {
+		b.tokens += now.Sub(b.at).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.at = now
+	}
+}
+
+// wait returns how long until a token is available
+func (b *bucket) wait(now time.Time) time.Duration {
+	b.refill(now)
+	if b.tokens >= 1-tokenTolerance {
+		return 0
+	}
+	return time.Duration(math.Ceil((1 - b.tokens) / b.rate * float64(time.Second)))
+}
+
+func (b *bucket) take(now time.Time) {
+	b.refill(now)
+	b.tokens--
+}
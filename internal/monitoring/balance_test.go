@@ -0,0 +1,438 @@
+package monitoring
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/clock"
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"go.uber.org/zap"
+)
+
+// runs 'balance' until the test finishes
+func startTestBalance(t *testing.T, b *balancer) (chan<- *Task, <-chan *Task) {
+	in := make(chan *Task)
+	tasks := make(chan *Task)
+	go b.run(in, tasks)
+
+	t.Cleanup(func() {
+		close(in)
+		for range tasks {
+		}
+	})
+
+	return in, tasks
+}
+
+func testTask(urlId string, userId string, host string) *Task {
+	return &Task{UrlId: model.ID(urlId), UserId: model.ID(userId), URL: "https://" + host + "/" + urlId}
+}
+
+func expectTask(t *testing.T, tasks <-chan *Task, urlId string) *Task {
+	t.Helper()
+
+	select {
+	case task := <-tasks:
+		if task.UrlId != model.ID(urlId) {
+			t.Fatalf("expected url %v, got %v", urlId, task.UrlId)
+		}
+		return task
+	case <-time.After(time.Second):
+		t.Fatalf("url %v was not sent to workers", urlId)
+		return nil
+	}
+}
+
+// expects the tasks of the urls in any order
+func expectTasks(t *testing.T, tasks <-chan *Task, urlIds ...string) map[string]*Task {
+	t.Helper()
+
+	expected := make(map[string]bool)
+	for _, id := range urlIds {
+		expected[id] = true
+	}
+
+	received := make(map[string]*Task)
+	for range urlIds {
+		select {
+		case task := <-tasks:
+			if !expected[string(task.UrlId)] {
+				t.Fatalf("unexpected task of url %v", task.UrlId)
+			}
+			received[string(task.UrlId)] = task
+		case <-time.After(time.Second):
+			t.Fatalf("urls %v were not sent to workers, got %d", urlIds, len(received))
+		}
+	}
+
+	return received
+}
+
+func expectNoTask(t *testing.T, tasks <-chan *Task) {
+	t.Helper()
+
+	select {
+	case task := <-tasks:
+		t.Fatalf("unexpected task of url %v", task.UrlId)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBalanceConcurrency(t *testing.T) {
+	b := newBalancer(zap.NewNop(), Config{
+		HostLimit: Limit{Concurrency: 1},
+		UserLimit: Limit{Concurrency: 2},
+	}, clock.NewFake(testStart))
+	in, tasks := startTestBalance(t, b)
+
+	in <- testTask("a1", "1", "a.com")
+	in <- testTask("a2", "1", "a.com")
+	in <- testTask("b1", "1", "b.com")
+	in <- testTask("c1", "1", "c.com")
+	in <- testTask("d1", "2", "d.com")
+
+	// a2 waits for its host, and c1 for its user
+	started := expectTasks(t, tasks, "a1", "b1", "d1")
+	expectNoTask(t, tasks)
+
+	started["a1"].finish()
+	expectTask(t, tasks, "a2")
+	expectNoTask(t, tasks)
+
+	started["b1"].finish()
+	expectTask(t, tasks, "c1")
+}
+
+func TestBalanceRate(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	b := newBalancer(zap.NewNop(), Config{HostLimit: Limit{Rate: 0.5, Burst: 1}}, clk)
+	in, tasks := startTestBalance(t, b)
+
+	in <- testTask("a1", "1", "a.com")
+	in <- testTask("a2", "1", "a.com")
+	in <- testTask("b1", "1", "b.com")
+	// a check of a pending url is not repeated
+	in <- testTask("a2", "1", "a.com")
+
+	expectTasks(t, tasks, "a1", "b1")
+	expectNoTask(t, tasks)
+
+	clk.WaitForTimers(1)
+	clk.Advance(time.Second)
+	expectNoTask(t, tasks)
+
+	clk.WaitForTimers(1)
+	clk.Advance(time.Second)
+	expectTask(t, tasks, "a2")
+	expectNoTask(t, tasks)
+}
+
+func TestBalanceFairness(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	b := newBalancer(zap.NewNop(), Config{UserWeights: map[string]float64{"2": 2}}, clk)
+
+	for _, userId := range []string{"1", "2"} {
+		for i := 0; i < 6; i++ {
+			task := testTask(fmt.Sprintf("%s-%d", userId, i), userId, "a.com")
+			task.due = testStart.Add(-time.Duration(i) * time.Second)
+			b.add(task)
+		}
+	}
+
+	// user 2 gets two checks for each check of user 1, while both have due checks
+	order := ""
+	for {
+		p, _ := b.next(clk.Now())
+		if p == nil {
+			break
+		}
+		b.start(p, clk.Now())
+		order += string(p.task.UserId)
+	}
+
+	if order != "122122122111" {
+		t.Fatalf("unexpected order of users: %v", order)
+	}
+
+	metrics := b.metrics()["2"]
+	if metrics.Checks != 6 || metrics.Pending != 0 || metrics.LagSecondsMax != 5 || metrics.LagSecondsTotal != 15 {
+		t.Fatalf("unexpected metrics: %+v", metrics)
+	}
+}
+
+func TestBalanceHeldHost(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	b := newBalancer(zap.NewNop(), Config{HostLimit: Limit{Concurrency: 1}}, clk)
+
+	for _, id := range []string{"a1", "a2", "b1", "a3", "b2"} {
+		b.add(testTask(id, "1", id[:1]+".com"))
+	}
+
+	startNext := func() string {
+		p, _ := b.next(clk.Now())
+		if p == nil {
+			return ""
+		}
+		b.start(p, clk.Now())
+		return string(p.task.UrlId)
+	}
+
+	// the tasks of a host that reached its limit wait, without holding back the other hosts
+	for _, expected := range []string{"a1", "b1", ""} {
+		if id := startNext(); id != expected {
+			t.Fatalf("expected %q to start, got %q", expected, id)
+		}
+	}
+
+	// the released hosts continue in the order their tasks were due
+	b.release("b.com", "1")
+	b.release("a.com", "1")
+	for _, expected := range []string{"a2", "b2", ""} {
+		if id := startNext(); id != expected {
+			t.Fatalf("expected %q to start, got %q", expected, id)
+		}
+	}
+
+	if pending := b.metrics()["1"].Pending; pending != 1 {
+		t.Fatalf("expected 1 pending task, got %d", pending)
+	}
+}
+
+func TestWorkerPoolScaling(t *testing.T) {
+	requests := make(chan struct{}, 2)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- struct{}{}
+		<-release
+	}))
+	defer server.Close()
+
+	clk := clock.NewFake(testStart)
+	cfg := Config{
+		RequestTimeout:    5 * time.Second,
+		NumberOfWorkers:   1,
+		MaxWorkers:        2,
+		ScaleUpWait:       time.Second,
+		WorkerIdleTimeout: time.Minute,
+	}
+	pool := newWorkerPool(zap.NewNop(), cfg, clk)
+	b := newBalancer(zap.NewNop(), cfg, clk)
+	b.pool = pool
+
+	var wg sync.WaitGroup
+	in := make(chan *Task)
+	tasks := make(chan *Task)
+	out := make(chan *Result, 2)
+	pool.start(&wg, tasks, out)
+	go b.run(in, tasks)
+
+	in <- &Task{UrlId: "a", UserId: "1", URL: server.URL + "/a", due: clk.Now()}
+	<-requests
+	in <- &Task{UrlId: "b", UserId: "1", URL: server.URL + "/b", due: clk.Now()}
+
+	// b waits for the only worker, so the pool grows after it waits for ScaleUpWait
+	clk.WaitForTimers(2)
+	clk.Advance(time.Second)
+	select {
+	case <-requests:
+	case <-time.After(time.Second):
+		t.Fatal("the pool did not grow for the waiting task")
+	}
+	if pool.workers() != 2 {
+		t.Fatalf("expected 2 workers, got %d", pool.workers())
+	}
+
+	close(release)
+	<-out
+	<-out
+
+	// the idle worker over the minimum retires
+	clk.WaitForTimers(2)
+	clk.Advance(time.Minute)
+	for deadline := time.Now().Add(time.Second); pool.workers() != 1; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 1 worker after idling, got %d", pool.workers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// closing "in" stops the remaining worker
+	close(in)
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("workers did not stop")
+	}
+}
+
+func TestBalanceProbeSharing(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	b := newBalancer(zap.NewNop(), Config{ProbeSharingWindow: 2 * time.Second}, clk)
+
+	task := func(urlId string, userId string, due time.Duration) *Task {
+		s := testTask(urlId, userId, "a.com")
+		s.URL = "https://a.com/health"
+		s.due = testStart.Add(due)
+		return s
+	}
+	subscribers := func(s *Task) string {
+		ids := ""
+		for _, s := range s.probe.joined() {
+			ids += string(s.UrlId) + " "
+		}
+		return ids
+	}
+
+	a := task("a", "1", 0)
+	b.add(a)
+	b.add(task("b", "1", time.Second))
+	// too late for the probe of a
+	c := task("c", "1", 5*time.Second)
+	b.add(c)
+	// another user does not share the probe of a, so it is limited and charged for its own check
+	f := task("f", "2", time.Second)
+	b.add(f)
+
+	p, _ := b.next(clk.Now())
+	if p.task != a {
+		t.Fatalf("expected a to start, got %v", p.task.UrlId)
+	}
+	b.start(p, clk.Now())
+
+	// joins a, which started within the window before it was due
+	clk.Advance(2 * time.Second)
+	b.add(task("d", "1", 1500*time.Millisecond))
+	if ids := subscribers(a); ids != "a b d " {
+		t.Fatalf("unexpected subscribers of a: %v", ids)
+	}
+	if ids := subscribers(c); ids != "c " || subscribers(f) != "f " {
+		t.Fatalf("unexpected subscribers of c and f: %v, %v", subscribers(c), subscribers(f))
+	}
+
+	// the lag of the checks that joined a is observed when they join it, or when it starts
+	if metrics := b.metrics()["1"]; metrics.Checks != 3 || metrics.LagSecondsLast != 0.5 {
+		t.Fatalf("unexpected metrics: %+v", metrics)
+	}
+
+	// no task joins a probe after its result is sent
+	a.subscribers()
+	e := task("e", "1", time.Second)
+	b.add(e)
+	if e.probe == a.probe {
+		t.Fatal("e joined a closed probe")
+	}
+
+	if shared := b.sharedChecks(); shared != 2 {
+		t.Fatalf("expected 2 shared checks, got %d", shared)
+	}
+}
+
+func TestBalanceProbePruning(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	b := newBalancer(zap.NewNop(), Config{ProbeSharingWindow: 2 * time.Second}, clk)
+
+	startTask := func(urlId string) {
+		task := testTask(urlId, "1", "a.com")
+		task.due = clk.Now()
+		b.add(task)
+		p, _ := b.next(clk.Now())
+		b.start(p, clk.Now())
+	}
+
+	startTask("a")
+	clk.Advance(time.Second)
+	startTask("b")
+
+	// the probe of a is older than the window when c starts, so it is removed even if a is not checked again
+	clk.Advance(1500 * time.Millisecond)
+	startTask("c")
+	if _, ok := b.inflight[probeKey(testTask("a", "1", "a.com"))]; ok {
+		t.Fatal("the probe of a was not removed")
+	}
+	if len(b.inflight) != 2 {
+		t.Fatalf("expected the probes of b and c, got %d probes", len(b.inflight))
+	}
+}
+
+func TestWorkerPoolProbeSharing(t *testing.T) {
+	requests := make(chan string, 3)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r.URL.Path
+		<-release
+	}))
+	defer server.Close()
+
+	clk := clock.NewFake(testStart)
+	cfg := Config{RequestTimeout: 5 * time.Second, NumberOfWorkers: 1, ProbeSharingWindow: time.Second}
+	pool := newWorkerPool(zap.NewNop(), cfg, clk)
+	b := newBalancer(zap.NewNop(), cfg, clk)
+
+	var wg sync.WaitGroup
+	in := make(chan *Task)
+	tasks := make(chan *Task)
+	out := make(chan *Result, 3)
+	pool.start(&wg, tasks, out)
+	go b.run(in, tasks)
+
+	in <- &Task{UrlId: "a", UserId: "1", URL: server.URL + "/shared", due: clk.Now()}
+	<-requests
+	in <- &Task{UrlId: "b", UserId: "1", URL: server.URL + "/shared", due: clk.Now()}
+	// c is read after b is added, so b joined the running probe
+	in <- &Task{UrlId: "c", UserId: "2", URL: server.URL + "/other", due: clk.Now()}
+	close(release)
+
+	results := make(map[model.ID]int)
+	for i := 0; i < 3; i++ {
+		select {
+		case r := <-out:
+			results[r.Task.UrlId] = r.StatusCode
+		case <-time.After(time.Second):
+			t.Fatalf("expected 3 results, got %v", results)
+		}
+	}
+	for _, id := range []model.ID{"a", "b", "c"} {
+		if results[id] != http.StatusOK {
+			t.Fatalf("unexpected results: %v", results)
+		}
+	}
+
+	close(in)
+	wg.Wait()
+	close(requests)
+	for path := range requests {
+		if path != "/other" {
+			t.Fatalf("unexpected request to %v", path)
+		}
+	}
+}
+
+func BenchmarkBalanceHeldHost(b *testing.B) {
+	bal := newBalancer(zap.NewNop(), Config{HostLimit: Limit{Concurrency: 1}}, clock.NewFake(testStart))
+
+	// the tasks of the held host are pending during the benchmark
+	bal.add(testTask("held", "1", "held.com"))
+	p, _ := bal.next(testStart)
+	bal.start(p, testStart)
+	for i := 0; i < 10_000; i++ {
+		bal.add(testTask(fmt.Sprintf("held-%d", i), fmt.Sprintf("%d", i%100), "held.com"))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		host := fmt.Sprintf("%d.com", i)
+		bal.add(testTask(host, fmt.Sprintf("%d", i%100), host))
+		p, _ := bal.next(testStart)
+		bal.start(p, testStart)
+		bal.release(host, p.task.UserId)
+	}
+}
@@ -1,8 +1,69 @@
 package monitoring
 
-import "time"
+import (
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// Limit bounds the checks of a host or a user. zero fields are not limited
+type Limit struct {
+	// Concurrency is the most checks that run at the same time
+	Concurrency int `config:"concurrency"`
+	// Rate is the most checks started per second, in bursts of up to Burst checks
+	Rate  float64 `config:"rate"`
+	Burst int     `config:"burst"`
+}
 
 type Config struct {
-	RequestTimeout  time.Duration `config:"request_timeout"`
-	NumberOfWorkers int           `config:"number_of_workers"`
+	RequestTimeout       time.Duration `config:"request_timeout"`
+	NumberOfWorkers      int           `config:"number_of_workers"`
+	MinuteStatsRetention time.Duration `config:"minute_stats_retention"`
+	HourStatsRetention   time.Duration `config:"hour_stats_retention"`
+	AlertPolicy          AlertPolicy   `config:"alert_policy"`
+	NotificationTimeout  time.Duration `config:"notification_timeout"`
+	NotificationWorkers  int           `config:"notification_workers"`
+	// a notification is dead-lettered after it fails this many times
+	NotificationMaxAttempts int `config:"notification_max_attempts"`
+	// wait before the first retry of a notification, doubled after each failure
+	NotificationBackoff time.Duration `config:"notification_backoff"`
+	// base url of the api, notifications link to the stats of the url under it
+	PublicUrl string `config:"public_url"`
+	// urls that became due while the monitor was down are checked over this long on startup
+	CatchUpWindow time.Duration `config:"catch_up_window"`
+	// fraction of the interval of a url that its calls are offset by, derived from the url id. 0 disables it
+	Jitter float64 `config:"jitter"`
+	// checks of each host and user over these limits wait
+	HostLimit Limit `config:"host_limit"`
+	UserLimit Limit `config:"user_limit"`
+	// share of the busy workers of each user id, 1 by default
+	UserWeights map[string]float64 `config:"user_weights"`
+	// address that /debug/vars is served on, empty disables it
+	MetricsAddress string `config:"metrics_address"`
+	// the pool grows up to MaxWorkers when a check waits longer than ScaleUpWait, and shrinks after WorkerIdleTimeout
+	MaxWorkers        int           `config:"max_workers"`
+	ScaleUpWait       time.Duration `config:"scale_up_wait"`
+	WorkerIdleTimeout time.Duration `config:"worker_idle_timeout"`
+	// checks of a user to the same target due this close to each other share one request. 0 disables it
+	ProbeSharingWindow time.Duration `config:"probe_sharing_window"`
+}
+
+// StatsRetention returns how long the buckets of each resolution are kept. zero means forever
+func (c Config) StatsRetention() map[model.Resolution]time.Duration {
+	return map[model.Resolution]time.Duration{
+		model.ResolutionMinute: c.MinuteStatsRetention,
+		model.ResolutionHour:   c.HourStatsRetention,
+	}
+}
+
+// FinestResolution returns the finest resolution whose buckets from since are still kept at now
+func (c Config) FinestResolution(since time.Time, now time.Time) model.Resolution {
+	retention := c.StatsRetention()
+	for _, r := range model.Resolutions {
+		if retention[r] == 0 || now.Sub(since) <= retention[r] {
+			return r
+		}
+	}
+
+	return model.ResolutionDay
 }
@@ -0,0 +1,31 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/request"
+)
+
+func TestFinestResolutionExactRetention(t *testing.T) {
+	cfg := Config{MinuteStatsRetention: 24 * time.Hour, HourStatsRetention: 30 * 24 * time.Hour}
+
+	// the windows as long as a retention still use its buckets
+	for window, want := range map[string]model.Resolution{
+		"24h": model.ResolutionMinute,
+		"7d":  model.ResolutionHour,
+		"30d": model.ResolutionHour,
+	} {
+		req := request.Uptime{Window: window}
+		w := req.TimeWindow(testStart)
+		if got := cfg.FinestResolution(w.Start, testStart); got != want {
+			t.Errorf("%s: expected resolution %v, got %v", window, want, got)
+		}
+	}
+
+	// a window longer than the hour retention falls back to day buckets
+	if got := cfg.FinestResolution(testStart.Add(-31*24*time.Hour), testStart); got != model.ResolutionDay {
+		t.Errorf("expected day resolution, got %v", got)
+	}
+}
@@ -0,0 +1,86 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+)
+
+// queues the messages of alerts for the channels selected by the routing rules of the user
+type dispatcher struct {
+	dataStore store.Store
+	queue     *notificationQueue
+}
+
+func newDispatcher(dataStore store.Store, queue *notificationQueue) *dispatcher {
+	return &dispatcher{
+		dataStore: dataStore,
+		queue:     queue,
+	}
+}
+
+// route queues the message for the channels selected by the routing rules
+func (d *dispatcher) route(ctx context.Context, url *model.URL, msg *notification.Message) error {
+	channelIds, err := d.channelsOf(ctx, url, msg)
+	if err != nil {
+		return err
+	}
+
+	if len(channelIds) > 0 {
+		d.queue.enqueue(ctx, channelIds, msg)
+	}
+	return nil
+}
+
+// resolve queues the recovery message for every channel that any of the alerts was raised on, by routing or
+// by escalation, so the integrations that track the incident resolve it. the channels selected by the routing rules
+// for recoveries are added, unless the alert of the message was silenced
+func (d *dispatcher) resolve(ctx context.Context, url *model.URL, msg *notification.Message, alertIds []model.ID) error {
+	channelIds, err := d.dataStore.NotificationJob().GetTriggeredChannelIds(ctx, url.UserId, alertIds)
+	if err != nil {
+		return fmt.Errorf("could not get channels of alerts: %w", err)
+	}
+
+	if !msg.Alert.Silenced() {
+		routed, err := d.channelsOf(ctx, url, msg)
+		if err != nil {
+			return err
+		}
+		channelIds = union(channelIds, routed)
+	}
+
+	if len(channelIds) > 0 {
+		d.queue.enqueue(ctx, channelIds, msg)
+	}
+	return nil
+}
+
+// channelsOf returns the channels selected by the routing rules for the message
+func (d *dispatcher) channelsOf(ctx context.Context, url *model.URL, msg *notification.Message) ([]model.ID, error) {
+	rules, err := d.dataStore.RoutingRule().GetByUserId(ctx, url.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("could not get routing rules: %w", err)
+	}
+
+	_, channelIds := model.Route(rules, url, msg.Type, msg.Severity)
+	return channelIds, nil
+}
+
+// union returns the ids of a followed by the ids of b that are not in a
+func union(a, b []model.ID) []model.ID {
+	seen := make(map[model.ID]bool, len(a))
+	for _, id := range a {
+		seen[id] = true
+	}
+
+	for _, id := range b {
+		if !seen[id] {
+			seen[id] = true
+			a = append(a, id)
+		}
+	}
+	return a
+}
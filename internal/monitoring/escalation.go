@@ -0,0 +1,225 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/clock"
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	"go.uber.org/zap"
+)
+
+const (
+	// longest wait between reads of the due escalations
+	maxEscalationWait = time.Minute
+	// wait before retrying after the escalations could not be read
+	escalationRetryWait = 10 * time.Second
+)
+
+// executes the steps of escalation policies. escalations are stored, so they continue after a restart
+type escalator struct {
+	logger    *zap.Logger
+	dataStore store.Store
+	queue     *notificationQueue
+	results   *resultCache
+	clock     clock.Clock
+	wake      chan struct{}
+}
+
+func newEscalator(logger *zap.Logger, dataStore store.Store, queue *notificationQueue, results *resultCache, clk clock.Clock) *escalator {
+	return &escalator{
+		logger:    logger,
+		dataStore: dataStore,
+		queue:     queue,
+		results:   results,
+		clock:     clk,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// start escalates the alert using the escalation policy of the url, if it has one
+func (e *escalator) start(ctx context.Context, alert *model.Alert, url *model.URL) error {
+	if url.EscalationPolicyId == "" {
+		return nil
+	}
+
+	policy, err := e.dataStore.EscalationPolicy().Get(ctx, url.UserId, url.EscalationPolicyId)
+	if err != nil {
+		return fmt.Errorf("could not get escalation policy: %w", err)
+	}
+
+	if len(policy.Steps) == 0 {
+		return nil
+	}
+
+	escalation := &model.Escalation{
+		UserId:   alert.UserId,
+		AlertId:  alert.Id,
+		UrlId:    alert.UrlId,
+		PolicyId: policy.Id,
+		Step:     0,
+		NextAt:   alert.IssuedAt.Add(policy.Steps[0].After.Duration),
+		State:    model.EscalationStateActive,
+	}
+
+	if err := e.dataStore.Escalation().Add(ctx, escalation); err != nil {
+		return fmt.Errorf("could not add escalation: %w", err)
+	}
+
+	// the new escalation may be due before the one 'run' is waiting for
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// executes the due steps until shutdown. it sleeps until the next step is due or a new escalation is started
+func (e *escalator) run(shutdown <-chan int, done chan<- int) {
+	timer := e.clock.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-shutdown:
+			done <- 0
+			return
+		case <-e.wake:
+			clock.StopTimer(timer)
+		case <-timer.C():
+		}
+
+		timer.Reset(e.escalateDue(context.Background(), e.clock.Now()))
+	}
+}
+
+// executes the steps due at now and returns the time to wait for the next step
+func (e *escalator) escalateDue(ctx context.Context, now time.Time) time.Duration {
+	due, err := e.dataStore.Escalation().GetDue(ctx, now)
+	if err != nil {
+		e.logger.Error("error getting due escalations", zap.Error(err))
+		return escalationRetryWait
+	}
+
+	for _, escalation := range due {
+		if err := e.escalate(ctx, escalation); err != nil {
+			e.logger.Error("error escalating alert", zap.Error(err), zap.Any("escalation", escalation))
+		}
+	}
+
+	next, err := e.dataStore.Escalation().GetNext(ctx)
+	if err != nil {
+		e.logger.Error("error getting next escalation", zap.Error(err))
+		return escalationRetryWait
+	}
+
+	if next == nil {
+		return maxEscalationWait
+	}
+
+	wait := next.NextAt.Sub(e.clock.Now())
+	if wait > maxEscalationWait {
+		return maxEscalationWait
+	}
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// executes the next step of the escalation, or stops it if the alert does not need attention anymore
+func (e *escalator) escalate(ctx context.Context, escalation *model.Escalation) error {
+	alert, err := e.dataStore.Alert().Get(ctx, escalation.UserId, escalation.AlertId)
+	if err != nil {
+		var notFound store.NotFoundError
+		if !errors.As(err, &notFound) {
+			return fmt.Errorf("could not get alert: %w", err)
+		}
+		return e.stop(ctx, escalation)
+	}
+
+	stop, err := e.shouldStop(ctx, alert)
+	if err != nil {
+		return err
+	}
+	if stop {
+		return e.stop(ctx, escalation)
+	}
+
+	policy, err := e.dataStore.EscalationPolicy().Get(ctx, escalation.UserId, escalation.PolicyId)
+	if err != nil {
+		var notFound store.NotFoundError
+		if !errors.As(err, &notFound) {
+			return fmt.Errorf("could not get escalation policy: %w", err)
+		}
+		return e.stop(ctx, escalation)
+	}
+
+	if escalation.Step < len(policy.Steps) {
+		e.notify(ctx, alert, policy.Steps[escalation.Step])
+	}
+
+	escalation.Advance(policy, alert.IssuedAt)
+	if err := e.dataStore.Escalation().Update(ctx, escalation); err != nil {
+		return fmt.Errorf("could not update escalation: %w", err)
+	}
+
+	return nil
+}
+
+// an escalation stops when its alert is acknowledged or resolved, or when the incident of the alert is resolved
+func (e *escalator) shouldStop(ctx context.Context, alert *model.Alert) (bool, error) {
+	if alert.CurrentState() != model.AlertStateOpen {
+		return true, nil
+	}
+
+	if alert.IncidentId == "" {
+		return false, nil
+	}
+
+	incident, err := e.dataStore.Incident().Get(ctx, alert.UserId, alert.IncidentId)
+	if err != nil {
+		var notFound store.NotFoundError
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not get incident: %w", err)
+	}
+
+	return incident.State == model.IncidentStateResolved, nil
+}
+
+func (e *escalator) stop(ctx context.Context, escalation *model.Escalation) error {
+	escalation.State = model.EscalationStateStopped
+	if err := e.dataStore.Escalation().Update(ctx, escalation); err != nil {
+		return fmt.Errorf("could not stop escalation: %w", err)
+	}
+	return nil
+}
+
+// queues the notifications of the channels of the step
+func (e *escalator) notify(ctx context.Context, alert *model.Alert, step model.EscalationStep) {
+	msg := notification.NewMessage(alert, urlOf(ctx, e.logger, e.dataStore, alert), e.results.get(alert.UrlId))
+	e.queue.enqueue(ctx, step.ChannelIds, msg)
+}
+
+// returns the url of the alert. if it can not be read, the url is built from the alert
+func urlOf(ctx context.Context, logger *zap.Logger, dataStore store.Store, alert *model.Alert) *model.URL {
+	urls, err := dataStore.Url().GetByUserId(ctx, alert.UserId)
+	if err != nil {
+		logger.Error("error getting urls", zap.Error(err), zap.Any("alert_id", alert.Id))
+	}
+
+	for _, url := range urls {
+		if url.Id == alert.UrlId {
+			return url
+		}
+	}
+
+	return &model.URL{Id: alert.UrlId, UserId: alert.UserId, Url: alert.Url}
+}
@@ -0,0 +1,118 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/clock"
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	"go.uber.org/zap"
+)
+
+// sends the alerts of open incidents again to the channels that end alerts which are not sent again, e.g. alertmanager.
+// the alert is not sent again after a user resolved it, since the channel was told it is resolved
+type firingRefresher struct {
+	logger    *zap.Logger
+	dataStore store.Store
+	queue     *notificationQueue
+	results   *resultCache
+	clock     clock.Clock
+	interval  time.Duration
+}
+
+func newFiringRefresher(logger *zap.Logger, dataStore store.Store, queue *notificationQueue, results *resultCache, clk clock.Clock) *firingRefresher {
+	return &firingRefresher{
+		logger:    logger,
+		dataStore: dataStore,
+		queue:     queue,
+		results:   results,
+		clock:     clk,
+		interval:  notification.AlertmanagerRefreshInterval,
+	}
+}
+
+// refreshes the firing alerts every interval until shutdown
+func (f *firingRefresher) run(shutdown <-chan int, done chan<- int) {
+	timer := f.clock.NewTimer(f.interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-shutdown:
+			done <- 0
+			return
+		case <-timer.C():
+		}
+
+		if err := f.refresh(context.Background()); err != nil {
+			f.logger.Error("error refreshing firing alerts", zap.Error(err))
+		}
+		timer.Reset(f.interval)
+	}
+}
+
+// queues the last alert of each open incident for the refiring channels it was sent to
+func (f *firingRefresher) refresh(ctx context.Context) error {
+	incidents, err := f.dataStore.Incident().GetOpen(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get open incidents: %w", err)
+	}
+
+	for _, incident := range incidents {
+		if len(incident.AlertIds) == 0 {
+			continue
+		}
+		if err := f.refire(ctx, incident); err != nil {
+			f.logger.Error("error refreshing alert of incident", zap.Error(err), zap.Any("incident", incident))
+		}
+	}
+
+	return nil
+}
+
+func (f *firingRefresher) refire(ctx context.Context, incident *model.Incident) error {
+	alert, err := f.lastNotified(ctx, incident)
+	if err != nil || alert == nil || alert.CurrentState() == model.AlertStateResolved {
+		return err
+	}
+
+	triggered, err := f.dataStore.NotificationJob().GetTriggeredChannelIds(ctx, incident.UserId, incident.AlertIds)
+	if err != nil {
+		return fmt.Errorf("could not get channels of alerts: %w", err)
+	}
+
+	channelIds := make([]model.ID, 0)
+	for _, id := range triggered {
+		channel, err := f.dataStore.Channel().Get(ctx, incident.UserId, id)
+		if err != nil {
+			f.logger.Info("could not get channel", zap.Error(err), zap.Any("channel_id", id))
+			continue
+		}
+		if notification.RefiresWhileOpen(channel.Type) {
+			channelIds = append(channelIds, id)
+		}
+	}
+
+	if len(channelIds) > 0 {
+		f.queue.enqueue(ctx, channelIds, notification.NewMessage(alert, nil, f.results.get(incident.UrlId)))
+	}
+	return nil
+}
+
+// returns the last alert of the incident that was not silenced, or nil if all of them were silenced
+func (f *firingRefresher) lastNotified(ctx context.Context, incident *model.Incident) (*model.Alert, error) {
+	for i := len(incident.AlertIds) - 1; i >= 0; i-- {
+		alert, err := f.dataStore.Alert().Get(ctx, incident.UserId, incident.AlertIds[i])
+		if err != nil {
+			return nil, fmt.Errorf("could not get alert: %w", err)
+		}
+		if !alert.Silenced() {
+			return alert, nil
+		}
+	}
+
+	return nil, nil
+}
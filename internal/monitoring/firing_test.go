@@ -0,0 +1,88 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/clock"
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"go.uber.org/zap"
+)
+
+func TestRefreshFiringAlerts(t *testing.T) {
+	clk := clock.NewFake(testStart.Add(-time.Hour))
+	s, dataStore := newTestScheduler(clk)
+	ctx := context.Background()
+
+	url := testUrl("", time.Minute)
+	url.Tags = []string{"env=prod"}
+	if err := dataStore.Url().Add(ctx, &url); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	am := &model.Channel{UserId: url.UserId, Type: model.ChannelTypeAlertmanager, Url: "http://alertmanager"}
+	slack := &model.Channel{UserId: url.UserId, Type: model.ChannelTypeSlack, Url: "http://slack"}
+	for _, c := range []*model.Channel{am, slack} {
+		if err := dataStore.Channel().Add(ctx, c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	rule := &model.RoutingRule{UserId: url.UserId, ChannelIds: []model.ID{am.Id, slack.Id}}
+	if err := dataStore.RoutingRule().Add(ctx, rule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < url.Threshold; i++ {
+		collectResults(s, resultOf(url, 503))
+		clk.Advance(time.Minute)
+	}
+
+	jobsOf := func(channelId model.ID) int {
+		t.Helper()
+		jobs, err := dataStore.NotificationJob().GetByUserId(ctx, url.UserId, "", 100)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		n := 0
+		for _, job := range jobs {
+			if job.ChannelId == channelId && job.Type == model.AlertTypeDown {
+				n++
+			}
+		}
+		return n
+	}
+
+	// only alertmanager ends alerts that are not sent again
+	if err := s.refresher.refresh(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if am, slack := jobsOf(am.Id), jobsOf(slack.Id); am != 2 || slack != 1 {
+		t.Fatalf("expected the alert to be sent again to alertmanager only, got %d and %d jobs", am, slack)
+	}
+
+	// a later alert of the incident keeps the tags of the first one
+	incident := s.incidents.openOf(url.Id)
+	changed := url
+	changed.Tags = []string{"env=staging"}
+	s.raiseAlert(zap.NewNop(), &changed, model.AlertTypeDown, incident, clk.Now())
+	last, err := dataStore.Alert().Get(ctx, url.UserId, incident.AlertIds[len(incident.AlertIds)-1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(last.Tags) != 1 || last.Tags[0] != "env=prod" {
+		t.Fatalf("expected the tags of the first alert, got %v", last.Tags)
+	}
+
+	// the alerts resolved by a user are not sent again
+	if _, err := dataStore.Alert().UpdateState(ctx, url.UserId, last.Id, model.AlertStateChange{State: model.AlertStateResolved, ChangedBy: "1", ChangedAt: clk.Now()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	before := jobsOf(am.Id)
+	if err := s.refresher.refresh(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := jobsOf(am.Id); n != before {
+		t.Fatalf("a resolved alert was sent again: %d jobs, expected %d", n, before)
+	}
+}
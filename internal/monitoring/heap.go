@@ -53,6 +53,10 @@ func (h *Heap) Peek() *TimedURL {
 
 func NewHeap(urls ...*TimedURL) *Heap {
 	h := Heap(urls)
+	// heap.Init only sets the index of the urls it swaps
+	for i, u := range h {
+		u.index = i
+	}
 	heap.Init(&h)
 	return &h
 }
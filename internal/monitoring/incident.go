@@ -0,0 +1,144 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	"go.uber.org/zap"
+)
+
+// keeps track of the open incident of each url. it is only used by 'collect'
+type incidentTracker struct {
+	logger *zap.Logger
+	store  store.Incident
+	open   map[model.ID]*model.Incident // url id -> open incident
+}
+
+func newIncidentTracker(logger *zap.Logger, incidentStore store.Incident) *incidentTracker {
+	return &incidentTracker{
+		logger: logger,
+		store:  incidentStore,
+		open:   make(map[model.ID]*model.Incident),
+	}
+}
+
+// loads the incidents left open by the previous run
+func (t *incidentTracker) load(ctx context.Context) error {
+	incidents, err := t.store.GetOpen(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get open incidents: %w", err)
+	}
+
+	for _, incident := range incidents {
+		t.open[incident.UrlId] = incident
+	}
+
+	return nil
+}
+
+// returns the open incident of the url, or nil if there is none
+func (t *incidentTracker) openOf(urlId model.ID) *model.Incident {
+	return t.open[urlId]
+}
+
+// opens an incident on the first failure of a url, and resolves it on the first success.
+// returns the open incident of the url, or nil if there is none
+func (t *incidentTracker) track(ctx context.Context, r *Result, success bool, at time.Time) *model.Incident {
+	incident, isOpen := t.open[r.Task.UrlId]
+
+	if success {
+		if isOpen {
+			t.resolve(ctx, incident, at)
+		}
+		return nil
+	}
+
+	if !isOpen {
+		return t.openIncident(ctx, r, at)
+	}
+
+	incident.FailedChecks++
+	if lastStatusCode(incident) != r.StatusCode {
+		incident.Timeline = append(incident.Timeline, model.IncidentEvent{
+			Type:       model.IncidentEventCheckFailed,
+			At:         at,
+			StatusCode: r.StatusCode,
+		})
+	}
+
+	if err := t.store.Update(ctx, incident); err != nil {
+		t.logger.Error("error updating incident", zap.Error(err), zap.Any("incident", incident))
+	}
+
+	return incident
+}
+
+// links the alert to the incident
+func (t *incidentTracker) addAlert(ctx context.Context, incident *model.Incident, alert *model.Alert) {
+	incident.AlertIds = append(incident.AlertIds, alert.Id)
+	incident.Timeline = append(incident.Timeline, model.IncidentEvent{
+		Type:    model.IncidentEventAlert,
+		At:      alert.IssuedAt,
+		AlertId: alert.Id,
+	})
+
+	if err := t.store.Update(ctx, incident); err != nil {
+		t.logger.Error("error updating incident", zap.Error(err), zap.Any("incident", incident))
+	}
+}
+
+func (t *incidentTracker) openIncident(ctx context.Context, r *Result, at time.Time) *model.Incident {
+	incident := &model.Incident{
+		UserId:       r.Task.UserId,
+		UrlId:        r.Task.UrlId,
+		Url:          r.Task.URL,
+		State:        model.IncidentStateOpen,
+		StartedAt:    at,
+		FailedChecks: 1,
+		AlertIds:     make([]model.ID, 0),
+		Timeline: []model.IncidentEvent{
+			{Type: model.IncidentEventOpened, At: at, StatusCode: r.StatusCode},
+		},
+	}
+
+	t.logger.Debug("opening incident", zap.Any("incident", incident))
+	if err := t.store.Add(ctx, incident); err != nil {
+		t.logger.Error("error adding incident", zap.Error(err), zap.Any("incident", incident))
+		return nil
+	}
+
+	t.open[incident.UrlId] = incident
+	return incident
+}
+
+func (t *incidentTracker) resolve(ctx context.Context, incident *model.Incident, at time.Time) {
+	incident.State = model.IncidentStateResolved
+	incident.ResolvedAt = &at
+	incident.Timeline = append(incident.Timeline, model.IncidentEvent{
+		Type: model.IncidentEventResolved,
+		At:   at,
+	})
+
+	t.logger.Debug("resolving incident", zap.Any("incident", incident))
+	if err := t.store.Update(ctx, incident); err != nil {
+		t.logger.Error("error updating incident", zap.Error(err), zap.Any("incident", incident))
+		return
+	}
+
+	delete(t.open, incident.UrlId)
+}
+
+// returns the status code of the last failed check in the timeline. it is NoResponse if the check got no response
+func lastStatusCode(incident *model.Incident) int {
+	for i := len(incident.Timeline) - 1; i >= 0; i-- {
+		switch e := incident.Timeline[i]; e.Type {
+		case model.IncidentEventOpened, model.IncidentEventCheckFailed:
+			return e.StatusCode
+		}
+	}
+
+	return NoResponse
+}
@@ -0,0 +1,71 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+)
+
+// windows added or deleted through the api take effect within this
+const maintenanceRefreshInterval = 30 * time.Second
+
+// keeps the current maintenance windows. it is refreshed by 'update' and read by 'schedule' and 'collect'
+type maintenanceCache struct {
+	mutex   sync.RWMutex
+	store   store.Maintenance
+	windows []*model.Maintenance
+}
+
+func newMaintenanceCache(maintenanceStore store.Maintenance) *maintenanceCache {
+	return &maintenanceCache{store: maintenanceStore}
+}
+
+// refresh loads the windows that are current at now
+func (c *maintenanceCache) refresh(ctx context.Context, now time.Time) error {
+	windows, err := c.store.GetCurrent(ctx, now)
+	if err != nil {
+		return fmt.Errorf("could not get maintenance windows: %w", err)
+	}
+
+	// the windows are parsed once here, since they are checked for every result and notification
+	compiled := make([]*model.Maintenance, 0, len(windows))
+	invalid := make([]model.ID, 0)
+	for _, w := range windows {
+		cw, err := w.Compile()
+		if err != nil {
+			invalid = append(invalid, w.Id)
+			continue
+		}
+		compiled = append(compiled, cw)
+	}
+
+	c.mutex.Lock()
+	c.windows = compiled
+	c.mutex.Unlock()
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("skipped maintenance windows with invalid schedules: %v", invalid)
+	}
+	return nil
+}
+
+// check reports whether the url is in a maintenance window at t, and whether its checks should be skipped
+func (c *maintenanceCache) check(url *model.URL, at time.Time) (active bool, skip bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for _, w := range c.windows {
+		if !w.AppliesTo(url) || !w.ActiveAt(at) {
+			continue
+		}
+
+		active = true
+		skip = skip || w.SkipChecks
+	}
+
+	return active, skip
+}
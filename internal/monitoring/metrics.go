@@ -0,0 +1,57 @@
+package monitoring
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// the metrics are published once per process, for the first scheduler
+var publishMetrics sync.Once
+
+// publishes the metrics of the scheduler as expvar variables, served on /debug/vars
+func (s *Scheduler) publishMetrics() {
+	publishMetrics.Do(func() {
+		expvar.Publish("schedule_users", expvar.Func(func() any {
+			return s.balancer.metrics()
+		}))
+		expvar.Publish("workers", expvar.Func(func() any {
+			return s.pool.workers()
+		}))
+		expvar.Publish("shared_checks", expvar.Func(func() any {
+			return s.balancer.sharedChecks()
+		}))
+	})
+}
+
+// userMetrics shows how long the due checks of a user wait for the workers
+type userMetrics struct {
+	// checks that are due and wait for a worker or a limit
+	Pending int `json:"pending"`
+	// checks sent to workers
+	Checks int64 `json:"checks"`
+	// the time between when the checks were due and when they were sent to workers
+	LagSecondsTotal float64 `json:"lag_seconds_total"`
+	LagSecondsMax   float64 `json:"lag_seconds_max"`
+	LagSecondsLast  float64 `json:"lag_seconds_last"`
+}
+
+type lagStats struct {
+	count int64
+	total time.Duration
+	max   time.Duration
+	last  time.Duration
+}
+
+func (l *lagStats) observe(lag time.Duration) {
+	if lag < 0 {
+		lag = 0
+	}
+
+	l.count++
+	l.total += lag
+	l.last = lag
+	if lag > l.max {
+		l.max = lag
+	}
+}
@@ -1,17 +1,37 @@
 package monitoring
 
 import (
+	"hash/fnv"
 	"time"
 
 	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/robfig/cron/v3"
 )
 
 type Task struct {
 	UrlId  model.ID
 	URL    string
 	UserId model.ID
+	// the scheduled url, so its schedule can adapt to the result
+	timed *TimedURL
+	// releases the limits taken by the task
+	done func()
+	// when the check was due, for measuring the schedule lag
+	due time.Time
+	// the request shared with the checks of the same target. nil when probes are not shared
+	probe *probe
 }
 
+// finish is called by the worker when the request of the task is done
+func (t *Task) finish() {
+	if t.done != nil {
+		t.done()
+	}
+}
+
+// NoResponse is the status code of a check that got no response
+const NoResponse = 0
+
 type Result struct {
 	Task       *Task
 	StatusCode int
@@ -23,16 +43,169 @@ type TimedURL struct {
 	URL      string
 	UserId   model.ID
 	Interval time.Duration
+	Tags     []string
 	callTime time.Time
 	index    int
+	// calls are at phase past the multiples of the interval, when aligned
+	phase   time.Duration
+	aligned bool
+	// replaces the interval when set
+	schedule cron.Schedule
+	// the url is checked every failingInterval since failingSince, until it recovers
+	failing         *model.FailingPolicy
+	failingSince    time.Time
+	failingInterval time.Duration
+	lastCall        time.Time
+}
+
+// NewTimedURL returns the url to be called first one interval after now, or on the next time of its schedule.
+// the call time is zero if the url has a schedule that never runs
+func NewTimedURL(url model.URL, now time.Time) *TimedURL {
+	t := &TimedURL{
+		UrlId:    url.Id,
+		URL:      url.Url,
+		UserId:   url.UserId,
+		Interval: url.Interval.Duration,
+		Tags:     url.Tags,
+		schedule: scheduleOf(url),
+		failing:  url.FailingPolicy,
+	}
+	t.callTime = t.next(now) // for preventing starting the first call immediately
+
+	return t
+}
+
+// ResumeTimedURL returns the url to be called one interval after its last check, or on the next time of its schedule.
+// a url that was never checked is due at now
+func ResumeTimedURL(url model.URL, now time.Time) *TimedURL {
+	t := NewTimedURL(url, now)
+	if t.callTime.IsZero() {
+		return t
+	}
+
+	if url.LastCheckedAt == nil {
+		t.callTime = now
+	} else {
+		t.callTime = t.next(*url.LastCheckedAt)
+	}
+
+	return t
+}
+
+// NextRuns returns the next n call times of the url after now, as they are scheduled by a monitor with jitter
+func NextRuns(url model.URL, jitter float64, now time.Time, n int) []time.Time {
+	t := NewTimedURL(url, now).withPhase(jitter)
+
+	runs := make([]time.Time, 0, n)
+	for at := t.callTime; !at.IsZero() && len(runs) < n; at = t.next(at) {
+		runs = append(runs, at)
+	}
+
+	return runs
+}
+
+// never is the schedule of urls whose schedule can't be parsed. they are not called
+type never struct{}
+
+func (never) Next(time.Time) time.Time {
+	return time.Time{}
+}
+
+func scheduleOf(url model.URL) cron.Schedule {
+	if url.Schedule == nil {
+		return nil
+	}
+
+	schedule, err := url.Schedule.Parse()
+	if err != nil {
+		return never{}
+	}
+	return schedule
+}
+
+// withPhase aligns the calls of the url to an offset in its interval, derived from its id.
+// the first call is moved to the earliest aligned time within one interval before its call time
+func (t *TimedURL) withPhase(jitter float64) *TimedURL {
+	if jitter <= 0 || t.Interval <= 0 || t.schedule != nil {
+		return t
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+
+	span := uint64(float64(t.Interval) * jitter)
+	if span == 0 {
+		return t
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(t.UrlId))
+	t.phase = time.Duration(h.Sum64() % span)
+	t.aligned = true
+	t.callTime = t.next(t.callTime.Add(-t.Interval))
+
+	return t
+}
+
+// next returns the call time of the url after a call at the given time
+func (t *TimedURL) next(after time.Time) time.Time {
+	if !t.failingSince.IsZero() {
+		return after.Add(t.failingInterval)
+	}
+
+	if t.schedule != nil {
+		return t.schedule.Next(after)
+	}
+
+	if !t.aligned {
+		return after.Add(t.Interval)
+	}
+
+	offset := (after.UnixNano() - int64(t.phase)) % int64(t.Interval)
+	if offset < 0 {
+		offset += int64(t.Interval)
+	}
+	return after.Add(t.Interval - time.Duration(offset))
+}
+
+// observe updates the failing state of the url by the result of a check at now.
+// it reports whether the next call of the url changed
+func (t *TimedURL) observe(success bool, now time.Time) bool {
+	p := t.failing
+	if p == nil {
+		return false
+	}
+
+	switch {
+	case success && t.failingSince.IsZero():
+		return false
+	case success:
+		t.failingSince = time.Time{}
+		t.failingInterval = 0
+	case t.failingSince.IsZero():
+		t.failingSince = now
+		t.failingInterval = p.Interval.Duration
+	case p.SlowDownAfter.Duration > 0 && now.Sub(t.failingSince) >= p.SlowDownAfter.Duration:
+		if t.failingInterval >= p.MaxInterval.Duration {
+			return false
+		}
+		t.failingInterval = time.Duration(float64(t.failingInterval) * p.Backoff)
+		if t.failingInterval > p.MaxInterval.Duration {
+			t.failingInterval = p.MaxInterval.Duration
+		}
+	default:
+		return false
+	}
+
+	t.callTime = t.next(t.lastCall)
+	return true
 }
 
-func NewTimedURL(UrlId model.ID, URL string, UserId model.ID, Interval time.Duration) *TimedURL {
-	return &TimedURL{
-		UrlId:    UrlId,
-		URL:      URL,
-		UserId:   UserId,
-		Interval: Interval,
-		callTime: time.Now().Add(Interval), // for preventing starting the first call immediately
+// model returns the url fields needed for matching maintenance windows
+func (t *TimedURL) model() *model.URL {
+	return &model.URL{
+		Id:     t.UrlId,
+		UserId: t.UserId,
+		Url:    t.URL,
+		Tags:   t.Tags,
 	}
 }
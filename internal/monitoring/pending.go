@@ -0,0 +1,168 @@
+package monitoring
+
+import (
+	"container/heap"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+type pendingTask struct {
+	task *Task
+	host string
+	seq  uint64
+}
+
+// the pending tasks of a user. the tasks of each host are kept in the order they were due, and the hosts that may
+// allow their first task are ordered by it, so the earliest allowed task is found without reading the tasks of the
+// hosts that reached their limits
+type userQueue struct {
+	userId model.ID
+	hosts  map[string]*hostTasks
+	ready  hostHeap
+	// the count of the pending tasks of all hosts
+	pending int
+	weight  float64
+	// the virtual time after the last started check of the user. it is not behind the virtual time of the balancer
+	// while the user is in the ready users
+	vtime float64
+	lag   lagStats
+	// the index in the ready users of the balancer. -1 when it is not in them
+	index int
+}
+
+// the pending tasks of a user to a host, in the order they were due
+type hostTasks struct {
+	host  string
+	tasks []*pendingTask
+	queue *userQueue
+	// the index in the ready hosts of the user. -1 when the host reached its limits
+	index int
+}
+
+func newUserQueue(userId model.ID, weight float64) *userQueue {
+	return &userQueue{
+		userId: userId,
+		hosts:  make(map[string]*hostTasks),
+		weight: weight,
+		index:  -1,
+	}
+}
+
+// push adds the task after the other tasks of its host. it returns the tasks of the host if they are new
+func (q *userQueue) push(p *pendingTask) (*hostTasks, bool) {
+	q.pending++
+
+	h, ok := q.hosts[p.host]
+	if ok {
+		h.tasks = append(h.tasks, p)
+		return h, false
+	}
+
+	h = &hostTasks{host: p.host, tasks: []*pendingTask{p}, queue: q, index: -1}
+	q.hosts[p.host] = h
+	return h, true
+}
+
+// remove removes the first task of the host, which is ready
+func (q *userQueue) remove(p *pendingTask) {
+	h := q.hosts[p.host]
+	h.tasks[0] = nil
+	h.tasks = h.tasks[1:]
+	q.pending--
+
+	if len(h.tasks) == 0 {
+		heap.Remove(&q.ready, h.index)
+		delete(q.hosts, p.host)
+		return
+	}
+	heap.Fix(&q.ready, h.index)
+}
+
+// the earliest task of the ready hosts
+func (q *userQueue) first() *pendingTask {
+	return q.ready[0].tasks[0]
+}
+
+// the hosts of a user, by the order their first tasks were due
+type hostHeap []*hostTasks
+
+// should not be called directly
+func (h *hostHeap) Len() int {
+	return len(*h)
+}
+
+// should not be called directly
+func (h *hostHeap) Less(i, j int) bool {
+	return (*h)[i].tasks[0].seq < (*h)[j].tasks[0].seq
+}
+
+// should not be called directly
+func (h *hostHeap) Swap(i, j int) {
+	(*h)[i], (*h)[j] = (*h)[j], (*h)[i]
+
+	(*h)[i].index = i
+	(*h)[j].index = j
+}
+
+// should not be called directly
+func (h *hostHeap) Push(x any) {
+	item := x.(*hostTasks)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+// should not be called directly
+func (h *hostHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[0 : n-1]
+
+	return item
+}
+
+// the users by their virtual times. equal virtual times are broken by the order their earliest tasks were due
+type userHeap []*userQueue
+
+// should not be called directly
+func (h *userHeap) Len() int {
+	return len(*h)
+}
+
+// should not be called directly
+func (h *userHeap) Less(i, j int) bool {
+	a, b := (*h)[i], (*h)[j]
+	if a.vtime != b.vtime {
+		return a.vtime < b.vtime
+	}
+	return a.first().seq < b.first().seq
+}
+
+// should not be called directly
+func (h *userHeap) Swap(i, j int) {
+	(*h)[i], (*h)[j] = (*h)[j], (*h)[i]
+
+	(*h)[i].index = i
+	(*h)[j].index = j
+}
+
+// should not be called directly
+func (h *userHeap) Push(x any) {
+	item := x.(*userQueue)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+// should not be called directly
+func (h *userHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[0 : n-1]
+
+	return item
+}
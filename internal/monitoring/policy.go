@@ -0,0 +1,110 @@
+package monitoring
+
+import (
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// AlertPolicy controls when alerts are raised for a url
+type AlertPolicy struct {
+	Cooldown           time.Duration `config:"cooldown"`
+	SuppressDuplicates bool          `config:"suppress_duplicates"`
+	FlapWindow         time.Duration `config:"flap_window"`
+	FlapThreshold      int           `config:"flap_threshold"`
+}
+
+// Override returns the policy with the fields set in the policy of a url replaced
+func (p AlertPolicy) Override(o model.AlertPolicy) AlertPolicy {
+	if o.Cooldown != nil {
+		p.Cooldown = o.Cooldown.Duration
+	}
+	if o.SuppressDuplicates != nil {
+		p.SuppressDuplicates = *o.SuppressDuplicates
+	}
+	if o.FlapWindow != nil {
+		p.FlapWindow = o.FlapWindow.Duration
+	}
+	if o.FlapThreshold != nil {
+		p.FlapThreshold = *o.FlapThreshold
+	}
+	return p
+}
+
+// alerting state of a url
+type urlAlertState struct {
+	known     bool
+	up        bool
+	changes   []time.Time // state changes in the flap window
+	flapping  bool
+	lastAlert time.Time
+}
+
+// decides which alerts are raised according to the alert policies. it is only used by 'collect'
+type alertGate struct {
+	urls map[model.ID]*urlAlertState
+}
+
+func newAlertGate() *alertGate {
+	return &alertGate{urls: make(map[model.ID]*urlAlertState)}
+}
+
+func (g *alertGate) state(urlId model.ID) *urlAlertState {
+	st, ok := g.urls[urlId]
+	if !ok {
+		st = &urlAlertState{}
+		g.urls[urlId] = st
+	}
+	return st
+}
+
+// observe records the result of a check and reports whether the url has just started flapping
+func (g *alertGate) observe(urlId model.ID, up bool, at time.Time, policy AlertPolicy) bool {
+	st := g.state(urlId)
+
+	if st.known && st.up != up {
+		st.changes = append(st.changes, at)
+	}
+	st.known = true
+	st.up = up
+
+	// forget the changes out of the window
+	kept := st.changes[:0]
+	for _, c := range st.changes {
+		if at.Sub(c) <= policy.FlapWindow {
+			kept = append(kept, c)
+		}
+	}
+	st.changes = kept
+
+	isFlapping := policy.FlapThreshold > 0 && len(st.changes) >= policy.FlapThreshold
+	started := isFlapping && !st.flapping
+	st.flapping = isFlapping
+
+	return started
+}
+
+// allowDown reports whether a down alert can be raised for the url.
+// down alerts are not raised while the url is flapping, in the cooldown, or if the open incident already has an alert
+func (g *alertGate) allowDown(urlId model.ID, incident *model.Incident, at time.Time, policy AlertPolicy) bool {
+	st := g.state(urlId)
+
+	if st.flapping {
+		return false
+	}
+
+	if !st.lastAlert.IsZero() && at.Sub(st.lastAlert) < policy.Cooldown {
+		return false
+	}
+
+	if policy.SuppressDuplicates && incident != nil && len(incident.AlertIds) > 0 {
+		return false
+	}
+
+	return true
+}
+
+// alerted records that an alert has been raised for the url
+func (g *alertGate) alerted(urlId model.ID, at time.Time) {
+	g.state(urlId).lastAlert = at
+}
@@ -0,0 +1,125 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/clock"
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+func TestAlertGateFlapping(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	g := newAlertGate()
+	policy := AlertPolicy{FlapWindow: 10 * time.Minute, FlapThreshold: 3}
+
+	// the first check is not a change, and the url changes state every 2 minutes after it
+	checks := []struct {
+		up      bool
+		started bool
+	}{
+		{up: true},
+		{up: false},
+		{up: true},
+		// the third change within the window
+		{up: false, started: true},
+		// still flapping, so no new flapping alert
+		{up: true},
+		{up: false},
+	}
+
+	for i, c := range checks {
+		if started := g.observe("1", c.up, clk.Now(), policy); started != c.started {
+			t.Fatalf("check %d: expected started flapping %v, got %v", i, c.started, started)
+		}
+		if i >= 3 && g.allowDown("1", nil, clk.Now(), policy) {
+			t.Fatalf("check %d: down alerts should not be raised while flapping", i)
+		}
+		clk.Advance(2 * time.Minute)
+	}
+
+	// the changes leave the window while the url stays down, so it stops flapping
+	clk.Advance(10 * time.Minute)
+	g.observe("1", false, clk.Now(), policy)
+	if !g.allowDown("1", nil, clk.Now(), policy) {
+		t.Fatal("down alerts should be raised after flapping stops")
+	}
+
+	// flapping starts again after it stopped
+	started := false
+	for i := 0; i < 3; i++ {
+		clk.Advance(time.Minute)
+		started = g.observe("1", i%2 == 0, clk.Now(), policy)
+	}
+	if !started {
+		t.Fatal("expected the url to start flapping again")
+	}
+}
+
+func TestAlertGateFlappingOutsideWindow(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	g := newAlertGate()
+	policy := AlertPolicy{FlapWindow: 10 * time.Minute, FlapThreshold: 3}
+
+	// the changes are 6 minutes apart, so at most 2 are in the window
+	up := true
+	for i := 0; i < 6; i++ {
+		if g.observe("1", up, clk.Now(), policy) {
+			t.Fatalf("check %d: the url should not be flapping", i)
+		}
+		up = !up
+		clk.Advance(6 * time.Minute)
+	}
+}
+
+func TestAlertGateSuppression(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	g := newAlertGate()
+	policy := AlertPolicy{SuppressDuplicates: true}
+
+	incident := &model.Incident{Id: "1", AlertIds: make([]model.ID, 0)}
+	g.observe("1", false, clk.Now(), policy)
+	if !g.allowDown("1", incident, clk.Now(), policy) {
+		t.Fatal("the first alert of an incident should be raised")
+	}
+	g.alerted("1", clk.Now())
+	incident.AlertIds = append(incident.AlertIds, "1")
+
+	clk.Advance(time.Hour)
+	g.observe("1", false, clk.Now(), policy)
+	if g.allowDown("1", incident, clk.Now(), policy) {
+		t.Fatal("a duplicate alert of the incident should be suppressed")
+	}
+
+	// a new incident is alerted again
+	if !g.allowDown("1", &model.Incident{Id: "2", AlertIds: make([]model.ID, 0)}, clk.Now(), policy) {
+		t.Fatal("the first alert of a new incident should be raised")
+	}
+
+	// without suppression, every alert of the incident is raised
+	policy.SuppressDuplicates = false
+	if !g.allowDown("1", incident, clk.Now(), policy) {
+		t.Fatal("alerts should not be suppressed when duplicates are allowed")
+	}
+}
+
+func TestAlertGateCooldown(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	g := newAlertGate()
+	policy := AlertPolicy{Cooldown: 10 * time.Minute}
+
+	g.alerted("1", clk.Now())
+
+	clk.Advance(9 * time.Minute)
+	if g.allowDown("1", nil, clk.Now(), policy) {
+		t.Fatal("alerts should not be raised in the cooldown")
+	}
+	if !g.allowDown("2", nil, clk.Now(), policy) {
+		t.Fatal("the cooldown of a url should not affect other urls")
+	}
+
+	clk.Advance(time.Minute)
+	if !g.allowDown("1", nil, clk.Now(), policy) {
+		t.Fatal("alerts should be raised after the cooldown")
+	}
+}
@@ -0,0 +1,151 @@
+package monitoring
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/clock"
+	"go.uber.org/zap"
+)
+
+// runs between min and max workers that read from "tasks". 'balance' grows the pool when a due task waits
+// for a worker longer than scaleUpWait, and a worker retires after it is idle for idleTimeout, while more than
+// min workers run. every worker exits when "tasks" is closed
+type workerPool struct {
+	logger         *zap.Logger
+	clock          clock.Clock
+	min            int
+	max            int
+	scaleUpWait    time.Duration
+	idleTimeout    time.Duration
+	requestTimeout time.Duration
+
+	mutex  sync.Mutex
+	size   int
+	nextId int
+
+	// set by start
+	wg    *sync.WaitGroup
+	tasks <-chan *Task
+	out   chan<- *Result
+}
+
+func newWorkerPool(logger *zap.Logger, cfg Config, clk clock.Clock) *workerPool {
+	p := &workerPool{
+		logger:         logger,
+		clock:          clk,
+		min:            cfg.NumberOfWorkers,
+		max:            cfg.MaxWorkers,
+		scaleUpWait:    cfg.ScaleUpWait,
+		idleTimeout:    cfg.WorkerIdleTimeout,
+		requestTimeout: cfg.RequestTimeout,
+	}
+	if p.min < 1 {
+		p.min = 1
+	}
+	if p.max < p.min {
+		p.max = p.min
+	}
+	return p
+}
+
+// start runs the minimum workers. wg is done when all the workers exit
+func (p *workerPool) start(wg *sync.WaitGroup, tasks <-chan *Task, out chan<- *Result) {
+	p.wg, p.tasks, p.out = wg, tasks, out
+	for i := 0; i < p.min; i++ {
+		p.grow()
+	}
+}
+
+// elastic reports whether the pool can grow beyond its minimum
+func (p *workerPool) elastic() bool {
+	return p.max > p.min
+}
+
+// grow adds a worker if the pool is not full. it must not be called after "tasks" is closed
+func (p *workerPool) grow() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.size >= p.max {
+		return false
+	}
+
+	p.size++
+	p.nextId++
+	// the count of the running workers stays positive until "tasks" is closed, so adding to it is safe
+	p.wg.Add(1)
+	go p.work(NewWorker(p.requestTimeout, p.logger.Named(fmt.Sprintf("worker(%d)", p.nextId))))
+
+	if p.size > p.min {
+		p.logger.Debug("added a worker", zap.Int("workers", p.size))
+	}
+	return true
+}
+
+// retire removes an idle worker if more than the minimum are running
+func (p *workerPool) retire() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.size <= p.min {
+		return false
+	}
+
+	p.size--
+	p.logger.Debug("retired an idle worker", zap.Int("workers", p.size))
+	return true
+}
+
+func (p *workerPool) workers() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.size
+}
+
+func (p *workerPool) work(w *Worker) {
+	defer p.wg.Done()
+
+	// workers of a fixed pool never retire
+	var idle <-chan time.Time
+	timer := p.clock.NewTimer(p.idleTimeout)
+	defer timer.Stop()
+	if p.elastic() {
+		idle = timer.C()
+	} else {
+		clock.StopTimer(timer)
+	}
+
+	for {
+		select {
+		case t, ok := <-p.tasks:
+			if !ok {
+				p.mutex.Lock()
+				p.size--
+				p.mutex.Unlock()
+				return
+			}
+
+			r, ok := w.Process(t)
+			t.finish()
+			// the probe is closed even if it failed, so no task waits for its result
+			subscribers := t.subscribers()
+			if ok {
+				for _, s := range subscribers {
+					p.out <- &Result{Task: s, StatusCode: r.StatusCode, Body: r.Body}
+				}
+			}
+		case <-idle:
+			if p.retire() {
+				return
+			}
+		}
+
+		if idle != nil {
+			clock.StopTimer(timer)
+			timer.Reset(p.idleTimeout)
+		}
+	}
+}
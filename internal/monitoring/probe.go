@@ -0,0 +1,67 @@
+package monitoring
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// probe is one request shared by the tasks of a user to identical targets that are due within the sharing window.
+// its result is sent to 'collect' once for each task, so the stats and the alerts of every url are updated
+type probe struct {
+	mutex  sync.Mutex
+	tasks  []*Task
+	closed bool
+
+	// only accessed by 'balance'
+	key     string
+	started time.Time
+}
+
+func newProbe(t *Task) *probe {
+	return &probe{tasks: []*Task{t}, key: probeKey(t)}
+}
+
+// probeKey identifies the request of the task. tasks with the same key can share a probe.
+// the key includes the user, so a check does not bypass the limits and the share of its user by joining a probe of another user
+func probeKey(t *Task) string {
+	return string(t.UserId) + " " + http.MethodGet + " " + t.URL
+}
+
+// join adds the task to the probe. it returns false if the result of the probe is already sent
+func (p *probe) join(t *Task) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return false
+	}
+
+	p.tasks = append(p.tasks, t)
+	return true
+}
+
+// joined returns the tasks that joined the probe so far, including the first one
+func (p *probe) joined() []*Task {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]*Task(nil), p.tasks...)
+}
+
+// close returns the tasks of the probe, and no more tasks can join it
+func (p *probe) close() []*Task {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.closed = true
+	return p.tasks
+}
+
+// subscribers returns the tasks that share the probe of the task, and no more tasks can join it
+func (t *Task) subscribers() []*Task {
+	if t.probe == nil {
+		return []*Task{t}
+	}
+	return t.probe.close()
+}
@@ -0,0 +1,222 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/clock"
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	"go.uber.org/zap"
+)
+
+const (
+	// a claimed job is claimed again after this long, if the worker that claimed it did not update it.
+	// it is longer than a notification can take with its rate limit retries
+	notificationLease = 5 * time.Minute
+	// jobs the api adds on acknowledge and resolve, or redrives, are sent within this
+	maxNotificationWait = time.Minute
+	// wait before retrying after the jobs could not be read
+	notificationRetryWait = 10 * time.Second
+	// the backoff of retries does not grow beyond this
+	maxNotificationBackoff = time.Hour
+)
+
+// sends the stored notification jobs with a pool of workers. failed jobs are retried with exponential backoff,
+// and dead-lettered after maxAttempts. jobs are stored, so the pending ones are sent after a restart
+type notificationQueue struct {
+	logger      *zap.Logger
+	dataStore   store.Store
+	notifier    *notification.Notifier
+	workers     int
+	maxAttempts int
+	backoff     time.Duration
+	clock       clock.Clock
+	wake        chan struct{}
+}
+
+func newNotificationQueue(logger *zap.Logger, dataStore store.Store, notifier *notification.Notifier, cfg Config, clk clock.Clock) *notificationQueue {
+	return &notificationQueue{
+		logger:      logger,
+		dataStore:   dataStore,
+		notifier:    notifier,
+		workers:     cfg.NotificationWorkers,
+		maxAttempts: cfg.NotificationMaxAttempts,
+		backoff:     cfg.NotificationBackoff,
+		clock:       clk,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// enqueue stores a job for each channel. failures are logged, so one channel does not block the others
+func (q *notificationQueue) enqueue(ctx context.Context, channelIds []model.ID, msg *notification.Message) {
+	now := q.clock.Now()
+	for _, channelId := range channelIds {
+		job := model.NewNotificationJob(channelId, msg.Alert, msg.Type, msg.Severity, msg.Result, now)
+
+		if err := q.dataStore.NotificationJob().Add(ctx, job); err != nil {
+			q.logger.Error("error adding notification job", zap.Error(err), zap.Any("job", job))
+		}
+	}
+
+	q.signal()
+}
+
+// wakes up an idle worker to claim the new jobs
+func (q *notificationQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// starts the workers. they stop after "shutdown" is closed, leaving the jobs they have not claimed pending
+func (q *notificationQueue) run(shutdown <-chan struct{}, wg *sync.WaitGroup) {
+	wg.Add(q.workers)
+	for i := 0; i < q.workers; i++ {
+		go q.work(q.logger.Named(fmt.Sprintf("worker(%d)", i)), shutdown, wg)
+	}
+}
+
+// sends the due jobs until shutdown. it sleeps until the next job is due or a job is added
+func (q *notificationQueue) work(logger *zap.Logger, shutdown <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	timer := q.clock.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-shutdown:
+			return
+		case <-q.wake:
+			clock.StopTimer(timer)
+		case <-timer.C():
+		}
+
+		timer.Reset(q.sendDue(context.Background(), logger, shutdown))
+	}
+}
+
+// sends the due jobs one at a time and returns the time to wait for the next job
+func (q *notificationQueue) sendDue(ctx context.Context, logger *zap.Logger, shutdown <-chan struct{}) time.Duration {
+	for {
+		select {
+		case <-shutdown:
+			return 0
+		default:
+		}
+
+		job, err := q.dataStore.NotificationJob().Claim(ctx, q.clock.Now(), notificationLease)
+		if err != nil {
+			logger.Error("error claiming notification job", zap.Error(err))
+			return notificationRetryWait
+		}
+		if job == nil {
+			break
+		}
+
+		// there may be more due jobs, let another worker send them
+		q.signal()
+		q.send(ctx, logger, job)
+	}
+
+	next, err := q.dataStore.NotificationJob().GetNext(ctx)
+	if err != nil {
+		logger.Error("error getting next notification job", zap.Error(err))
+		return notificationRetryWait
+	}
+
+	if next == nil {
+		return maxNotificationWait
+	}
+
+	wait := next.NextAttemptAt.Sub(q.clock.Now())
+	if wait > maxNotificationWait {
+		return maxNotificationWait
+	}
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// sends the claimed job and stores its outcome
+func (q *notificationQueue) send(ctx context.Context, logger *zap.Logger, job *model.NotificationJob) {
+	err := q.notify(ctx, job)
+	now := q.clock.Now()
+
+	var gone errGone
+	switch {
+	case err == nil:
+		job.Succeed(now)
+	case errors.As(err, &gone):
+		job.Kill(err.Error(), now)
+	default:
+		job.Fail(err.Error(), now, now.Add(q.retryWait(job.Attempts, err)), q.maxAttempts)
+	}
+
+	if job.Status == model.NotificationJobStatusDead {
+		logger.Warn("notification job is dead", zap.Error(err), zap.Any("job", job))
+	} else if err != nil {
+		logger.Info("notification job failed", zap.Error(err), zap.Any("job", job))
+	}
+
+	if err := q.dataStore.NotificationJob().Update(ctx, job); err != nil {
+		logger.Error("error updating notification job", zap.Error(err), zap.Any("job", job))
+	}
+}
+
+// errGone means the channel or alert of the job was deleted, so it can never be sent
+type errGone struct {
+	error
+}
+
+func (q *notificationQueue) notify(ctx context.Context, job *model.NotificationJob) error {
+	channel, err := q.dataStore.Channel().Get(ctx, job.UserId, job.ChannelId)
+	if err != nil {
+		return goneIfNotFound(fmt.Errorf("could not get channel: %w", err))
+	}
+
+	alert, err := q.dataStore.Alert().Get(ctx, job.UserId, job.AlertId)
+	if err != nil {
+		return goneIfNotFound(fmt.Errorf("could not get alert: %w", err))
+	}
+
+	msg := notification.NewMessage(alert, urlOf(ctx, q.logger, q.dataStore, alert), job.Result)
+	msg.Type = job.Type
+	msg.Severity = job.Severity
+
+	return q.notifier.Notify(ctx, channel, msg)
+}
+
+func goneIfNotFound(err error) error {
+	var notFound store.NotFoundError
+	if errors.As(err, &notFound) {
+		return errGone{err}
+	}
+	return err
+}
+
+// returns the wait before the next attempt of a job that failed attempts times.
+// a rate limited job waits at least as long as the endpoint asked
+func (q *notificationQueue) retryWait(attempts int, err error) time.Duration {
+	wait := q.backoff
+	for i := 1; i < attempts && wait < maxNotificationBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxNotificationBackoff {
+		wait = maxNotificationBackoff
+	}
+
+	var rateLimited *notification.RateLimitedError
+	if errors.As(err, &rateLimited) && rateLimited.RetryAfter > wait {
+		wait = rateLimited.RetryAfter
+	}
+
+	return wait
+}
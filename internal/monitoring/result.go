@@ -0,0 +1,46 @@
+package monitoring
+
+import (
+	"sync"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
+)
+
+// bodies are only kept for notifications, so they are truncated
+const maxResultBodySize = 1024
+
+// keeps the latest result of each url. it is written by 'collect' and read when notifying
+type resultCache struct {
+	mutex   sync.RWMutex
+	results map[model.ID]*notification.CheckResult
+}
+
+func newResultCache() *resultCache {
+	return &resultCache{results: make(map[model.ID]*notification.CheckResult)}
+}
+
+func (c *resultCache) set(r *Result, at time.Time) {
+	body := r.Body
+	if len(body) > maxResultBodySize {
+		body = body[:maxResultBodySize]
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.results[r.Task.UrlId] = &notification.CheckResult{
+		StatusCode: r.StatusCode,
+		Body:       body,
+		CheckedAt:  at,
+	}
+}
+
+// get returns the latest result of the url, or nil if it has not been checked since the monitor started
+func (c *resultCache) get(urlId model.ID) *notification.CheckResult {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.results[urlId]
+}
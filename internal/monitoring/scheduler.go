@@ -1,13 +1,16 @@
 package monitoring
 
 import (
+	"container/heap"
 	"context"
-	"fmt"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/MeysamBavi/http-monitoring/internal/clock"
 	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
 	"github.com/MeysamBavi/http-monitoring/internal/store"
 	"github.com/MeysamBavi/http-monitoring/internal/util"
 	"go.uber.org/zap"
@@ -16,16 +19,57 @@ import (
 type Scheduler struct {
 	logger         *zap.Logger
 	numOfWorkers   int
-	requestTimeout time.Duration
+	pool           *workerPool
+	statsRetention map[model.Resolution]time.Duration
 	dataStore      store.Store
+	incidents      *incidentTracker
+	alertPolicy    AlertPolicy
+	alerts         *alertGate
+	maintenance    *maintenanceCache
+	escalator      *escalator
+	refresher      *firingRefresher
+	dispatcher     *dispatcher
+	results        *resultCache
+	queue          *notificationQueue
+	balancer       *balancer
+	clock          clock.Clock
+	catchUpWindow  time.Duration
+	jitter         float64
 }
 
-func NewScheduler(logger *zap.Logger, numOfWorkers int, requestTimeout time.Duration, dataStore store.Store) *Scheduler {
+func NewScheduler(logger *zap.Logger, cfg Config, dataStore store.Store) *Scheduler {
+	s := newScheduler(logger, cfg, dataStore, clock.System)
+	s.publishMetrics()
+	return s
+}
+
+// newScheduler returns a scheduler that reads the time from clk
+func newScheduler(logger *zap.Logger, cfg Config, dataStore store.Store, clk clock.Clock) *Scheduler {
+	notifier := notification.NewNotifier(logger.Named("notify"), cfg.NotificationTimeout, cfg.PublicUrl)
+	results := newResultCache()
+	queue := newNotificationQueue(logger.Named("notify"), dataStore, notifier, cfg, clk)
+	pool := newWorkerPool(logger, cfg, clk)
+	balancer := newBalancer(logger.Named("balance"), cfg, clk)
+	balancer.pool = pool
 	return &Scheduler{
-		logger,
-		numOfWorkers,
-		requestTimeout,
-		dataStore,
+		logger:         logger,
+		numOfWorkers:   cfg.NumberOfWorkers,
+		statsRetention: cfg.StatsRetention(),
+		dataStore:      dataStore,
+		incidents:      newIncidentTracker(logger.Named("incident"), dataStore.Incident()),
+		alertPolicy:    cfg.AlertPolicy,
+		alerts:         newAlertGate(),
+		maintenance:    newMaintenanceCache(dataStore.Maintenance()),
+		escalator:      newEscalator(logger.Named("escalate"), dataStore, queue, results, clk),
+		refresher:      newFiringRefresher(logger.Named("refresh"), dataStore, queue, results, clk),
+		results:        results,
+		dispatcher:     newDispatcher(dataStore, queue),
+		queue:          queue,
+		balancer:       balancer,
+		pool:           pool,
+		clock:          clk,
+		catchUpWindow:  cfg.CatchUpWindow,
+		jitter:         cfg.Jitter,
 	}
 }
 
@@ -40,48 +84,70 @@ func (s *Scheduler) Run(shutdown <-chan os.Signal) {
 // needed variables in run
 type scope struct {
 	// 'schedule' writes on "in"
-	// workers read from "in"
+	// 'balance' reads from "in" and writes on "tasks"
+	// workers read from "tasks"
 	// workers write on "out"
 	// 'collect' reads from "out"
 
 	shutdown         <-chan os.Signal
 	in               chan *Task
+	tasks            chan *Task
 	out              chan *Result
 	wg               sync.WaitGroup
 	scheduleShutdown chan int
 	updateShutdown   chan int
 	updateDone       chan int
 	collectDone      chan int
+	escalateShutdown chan int
+	escalateDone     chan int
+	refreshShutdown  chan int
+	refreshDone      chan int
+	notifyShutdown   chan struct{}
+	notifyWg         sync.WaitGroup
 	syncHeap         *util.SyncHeap[*TimedURL]
+	timedUrls        map[model.ID]*TimedURL // only accessed by 'update' after initialization
 }
 
 func (s *Scheduler) createScope(shutdown <-chan os.Signal) *scope {
 	return &scope{
 		shutdown:         shutdown,
 		in:               make(chan *Task, s.numOfWorkers),
+		tasks:            make(chan *Task),
 		out:              make(chan *Result, s.numOfWorkers),
 		scheduleShutdown: make(chan int),
 		updateShutdown:   make(chan int),
 		updateDone:       make(chan int),
 		collectDone:      make(chan int),
+		escalateShutdown: make(chan int),
+		escalateDone:     make(chan int),
+		refreshShutdown:  make(chan int),
+		refreshDone:      make(chan int),
+		notifyShutdown:   make(chan struct{}),
 		syncHeap:         nil,
 	}
 }
 
 func (s *Scheduler) startWorkers(scope *scope) {
-	scope.wg.Add(s.numOfWorkers)
-	for i := 0; i < s.numOfWorkers; i++ {
-		go NewWorker(s.requestTimeout, s.logger.Named(fmt.Sprintf("worker(%d)", i))).Work(&scope.wg, scope.in, scope.out)
-	}
+	s.pool.start(&scope.wg, scope.tasks, scope.out)
 }
 
 func (s *Scheduler) startModules(scope *scope) {
-	scope.syncHeap = s.initializeHeap()
+	scope.syncHeap, scope.timedUrls = s.initializeHeap()
+	if err := s.maintenance.refresh(context.Background(), s.clock.Now()); err != nil {
+		s.logger.Fatal("error loading maintenance windows", zap.Error(err))
+	}
+	if err := s.incidents.load(context.Background()); err != nil {
+		s.logger.Fatal("error loading incidents", zap.Error(err))
+	}
 	s.logger.Info("starting modules")
 
 	go s.schedule(scope.syncHeap, scope.in, scope.scheduleShutdown)
-	go s.update(scope.syncHeap, scope.updateShutdown, scope.updateDone)
-	go s.collect(scope.out, scope.collectDone)
+	go s.balancer.run(scope.in, scope.tasks)
+	go s.update(scope.syncHeap, scope.timedUrls, scope.updateShutdown, scope.updateDone)
+	go s.collect(scope.syncHeap, scope.out, scope.collectDone)
+	go s.escalator.run(scope.escalateShutdown, scope.escalateDone)
+	go s.refresher.run(scope.refreshShutdown, scope.refreshDone)
+	s.queue.run(scope.notifyShutdown, &scope.notifyWg)
 }
 
 func (s *Scheduler) waitForShutdown(scope *scope) {
@@ -93,7 +159,7 @@ func (s *Scheduler) waitForShutdown(scope *scope) {
 	<-scope.updateDone
 
 	s.logger.Info("stopping 'schedule' module")
-	scope.scheduleShutdown <- 0 // close "in"
+	scope.scheduleShutdown <- 0 // close "in", then 'balance' closes "tasks"
 
 	s.logger.Info("waiting for workers to finish")
 	scope.wg.Wait() // wait for workers to stop writing to "out"
@@ -101,67 +167,157 @@ func (s *Scheduler) waitForShutdown(scope *scope) {
 	close(scope.out) // close "out"
 	s.logger.Info("waiting for 'collect' module to finish writing to db")
 	<-scope.collectDone // wait for collect to complete working
+
+	s.logger.Info("stopping 'escalate' module")
+	scope.escalateShutdown <- 0
+	<-scope.escalateDone
+
+	s.logger.Info("stopping 'refresh' module")
+	scope.refreshShutdown <- 0
+	<-scope.refreshDone
+
+	// jobs that are not sent yet stay pending in the store
+	s.logger.Info("waiting for notification workers to finish")
+	close(scope.notifyShutdown)
+	scope.notifyWg.Wait()
 }
 
-func (s *Scheduler) initializeHeap() *util.SyncHeap[*TimedURL] {
+func (s *Scheduler) initializeHeap() (*util.SyncHeap[*TimedURL], map[model.ID]*TimedURL) {
 
 	s.logger.Info("initializing urls heap")
 
+	now := s.clock.Now()
 	all := make([]*TimedURL, 0)
+	byId := make(map[model.ID]*TimedURL)
 	err := s.dataStore.Url().ForAll(context.Background(), func(u model.URL) {
-		t := NewTimedURL(u.Id, u.Url, u.UserId, u.Interval.Duration)
+		if u.Paused {
+			return
+		}
+		t := ResumeTimedURL(u, now).withPhase(s.jitter)
+		if t.callTime.IsZero() {
+			s.logger.Warn("url has no scheduled calls", zap.Any("url_id", u.Id))
+			return
+		}
 		all = append(all, t)
+		byId[u.Id] = t
 	})
 
 	if err != nil {
 		s.logger.Fatal("error reading all urls", zap.Error(err))
 	}
 
-	return util.NewSyncHeap[*TimedURL](NewHeap(all...))
+	spreadCatchUp(all, now, s.catchUpWindow)
+	return util.NewSyncHeap[*TimedURL](NewHeap(all...)), byId
+}
+
+// spreads the urls that are overdue at now over window, the most overdue first,
+// so a restart does not send all of them to the workers at once. no url is delayed past its next regular call
+func spreadCatchUp(urls []*TimedURL, now time.Time, window time.Duration) {
+	overdue := make([]*TimedURL, 0)
+	for _, t := range urls {
+		if !t.callTime.After(now) {
+			overdue = append(overdue, t)
+		}
+	}
+
+	sort.SliceStable(overdue, func(i, j int) bool {
+		return overdue[i].callTime.Before(overdue[j].callTime)
+	})
+
+	for i, t := range overdue {
+		delay := window * time.Duration(i) / time.Duration(len(overdue))
+		if limit := t.next(now).Sub(now); delay > limit {
+			delay = limit
+		}
+		t.callTime = now.Add(delay)
+	}
 }
 
-// writes to "in" and closes it when shutdown signal is received
+// writes to "in" and closes it when shutdown signal is received.
+// it sleeps on a single timer until the earliest url is due, and wakes up early when the heap changes
 func (s *Scheduler) schedule(syncedHeap *util.SyncHeap[*TimedURL], in chan<- *Task, shutdown <-chan int) {
 	logger := s.logger.Named("schedule")
 
+	timer := s.clock.NewTimer(0)
+	defer timer.Stop()
+	clock.StopTimer(timer)
+
 	for {
+		// the call time is read under the lock, since 'collect' reschedules failing urls
+		var earliestUrl *TimedURL
+		var callTime time.Time
+		syncedHeap.View(func(h util.CustomHeapInterface[*TimedURL]) {
+			if h.Len() > 0 {
+				earliestUrl = h.Peek()
+				callTime = earliestUrl.callTime
+			}
+		})
+
+		ok := earliestUrl != nil
+		if ok && !s.clock.Now().Before(callTime) {
+			if !s.dispatch(logger, syncedHeap, earliestUrl, callTime, in, shutdown) {
+				close(in)
+				return
+			}
+			continue
+		}
+
+		// an empty heap waits for a change only
+		if ok {
+			timer.Reset(callTime.Sub(s.clock.Now()))
+		}
+
 		select {
 		case <-shutdown:
 			close(in)
 			return
+		case <-syncedHeap.Changes():
+			clock.StopTimer(timer)
+		case <-timer.C():
+		}
+	}
+}
 
-		default:
-			if syncedHeap.Len() == 0 {
-				time.Sleep(time.Millisecond * 100)
-				continue
-			}
-			earliestUrl := syncedHeap.Peek()
-			if time.Now().Before(earliestUrl.callTime) {
-				max := time.Millisecond * 100
-				until := time.Until(earliestUrl.callTime)
-				if until < max {
-					time.Sleep(until)
-				} else {
-					time.Sleep(max)
-				}
-				continue
-			}
-
-			logger.Debug("sending this url to workers", zap.Any("url", earliestUrl))
-			in <- &Task{
-				UrlId:  earliestUrl.UrlId,
-				URL:    earliestUrl.URL,
-				UserId: earliestUrl.UserId,
-			}
+// sends the due url to workers, unless it is in maintenance, and reschedules it.
+// it returns false if shutdown was received while waiting for a worker
+func (s *Scheduler) dispatch(logger *zap.Logger, syncedHeap *util.SyncHeap[*TimedURL], url *TimedURL, due time.Time, in chan<- *Task, shutdown <-chan int) bool {
+	if _, skip := s.maintenance.check(url.model(), s.clock.Now()); skip {
+		logger.Debug("skipping this url during maintenance", zap.Any("url", url))
+	} else {
+		logger.Debug("sending this url to workers", zap.Any("url", url))
+		select {
+		case <-shutdown:
+			return false
+		case in <- &Task{UrlId: url.UrlId, URL: url.URL, UserId: url.UserId, timed: url, due: due}:
+		}
+	}
 
-			earliestUrl.callTime = time.Now().Add(earliestUrl.Interval)
-			syncedHeap.Fix(earliestUrl.index)
+	// the url may have been removed by 'update' in the meantime
+	syncedHeap.Do(func(h util.CustomHeapInterface[*TimedURL]) {
+		if url.index < 0 {
+			return
 		}
+		url.lastCall = s.clock.Now()
+		url.callTime = url.next(url.lastCall)
+		if url.callTime.IsZero() {
+			// the schedule has no more runs
+			heap.Remove(h, url.index)
+			return
+		}
+		heap.Fix(h, url.index)
+	})
+
+	// the loop reads the heap before it sleeps again, so the signal of its own change is not needed
+	select {
+	case <-syncedHeap.Changes():
+	default:
 	}
+
+	return true
 }
 
-// reads from db and updates heap
-func (s *Scheduler) update(syncHeap *util.SyncHeap[*TimedURL], shutdown <-chan int, done chan<- int) {
+// reads from db and updates heap. also refreshes the maintenance windows
+func (s *Scheduler) update(syncHeap *util.SyncHeap[*TimedURL], timedUrls map[model.ID]*TimedURL, shutdown <-chan int, done chan<- int) {
 	logger := s.logger.Named("update")
 
 	events, err := s.dataStore.Url().ListenForChanges(context.Background())
@@ -169,31 +325,63 @@ func (s *Scheduler) update(syncHeap *util.SyncHeap[*TimedURL], shutdown <-chan i
 		logger.Fatal("error listening for changes", zap.Error(err))
 	}
 
+	refresh := s.clock.NewTimer(maintenanceRefreshInterval)
+	defer refresh.Stop()
+
 	for {
 		select {
 		case <-shutdown:
 			done <- 0
 			return
+		case <-refresh.C():
+			if err := s.maintenance.refresh(context.Background(), s.clock.Now()); err != nil {
+				logger.Error("error refreshing maintenance windows", zap.Error(err))
+			}
+			refresh.Reset(maintenanceRefreshInterval)
 		case event, ok := <-events:
 			if !ok {
 				logger.Fatal("url events channel was closed unexpectedly")
 			}
 			logger.Debug("received event", zap.Any("event", event))
-			if event.Operation == store.UrlChangeOperationInsert {
-				logger.Debug("updating heap", zap.Any("event", event))
-				syncHeap.Push(NewTimedURL(
-					event.Url.Id,
-					event.Url.Url,
-					event.Url.UserId,
-					event.Url.Interval.Duration,
-				))
-			}
+
+			s.apply(syncHeap, timedUrls, event)
 		}
 	}
 }
 
+// applies the change of a url to the heap. an updated url is removed and pushed again with its new fields
+func (s *Scheduler) apply(syncHeap *util.SyncHeap[*TimedURL], timedUrls map[model.ID]*TimedURL, event store.UrlChangeEvent) {
+	switch event.Operation {
+	case store.UrlChangeOperationInsert, store.UrlChangeOperationUpdate, store.UrlChangeOperationDelete:
+	default:
+		return
+	}
+
+	if old, ok := timedUrls[event.Url.Id]; ok {
+		syncHeap.Do(func(h util.CustomHeapInterface[*TimedURL]) {
+			if old.index >= 0 {
+				heap.Remove(h, old.index)
+			}
+		})
+		delete(timedUrls, event.Url.Id)
+	}
+
+	if event.Operation == store.UrlChangeOperationDelete || event.Url.Paused {
+		return
+	}
+
+	t := NewTimedURL(event.Url, s.clock.Now()).withPhase(s.jitter)
+	if t.callTime.IsZero() {
+		s.logger.Warn("url has no scheduled calls", zap.Any("url_id", event.Url.Id))
+		return
+	}
+	timedUrls[event.Url.Id] = t
+	syncHeap.Push(t)
+}
+
 // reads from "out" and writes to database. sends signal on "done" when done
-func (s *Scheduler) collect(out <-chan *Result, done chan<- int) {
+// the urls in syncedHeap are rescheduled when their failing state changes
+func (s *Scheduler) collect(syncedHeap *util.SyncHeap[*TimedURL], out <-chan *Result, done chan<- int) {
 	logger := s.logger.Named("collect")
 
 	for r := range out {
@@ -207,36 +395,169 @@ func (s *Scheduler) collect(out <-chan *Result, done chan<- int) {
 			failure = 1
 		}
 
+		now := s.clock.Now()
+		s.adapt(syncedHeap, r.Task.timed, success == 1, now)
+		s.addStats(logger, r, now, success, failure)
+		s.results.set(r, now)
+		previous := s.incidents.openOf(r.Task.UrlId)
+		incident := s.incidents.track(context.Background(), r, success == 1, now)
+
 		statChange := model.DayStat{
-			Date:         model.Today(),
+			Date:         model.DateOf(now),
 			SuccessCount: success,
 			FailureCount: failure,
 		}
 
 		logger.Debug("saving this result to db", zap.Any("result", r), zap.Any("statChange", statChange))
-		url, stat, err := s.dataStore.Url().UpdateStat(context.Background(), r.Task.UserId, r.Task.UrlId, statChange)
+		url, stat, err := s.dataStore.Url().UpdateStat(context.Background(), r.Task.UserId, r.Task.UrlId, statChange, now)
 
 		if err != nil {
 			logger.Error("error updating stat", zap.Error(err), zap.Any("result", r), zap.Any("stat", stat))
 			continue
 		}
 
-		// send alert if it has passed failure threshold
-		if stat.FailureCount > 0 && stat.FailureCount%url.Threshold == 0 {
+		policy := s.alertPolicy.Override(url.AlertPolicy)
+		startedFlapping := s.alerts.observe(url.Id, success == 1, now, policy)
+
+		// recoveries are notified during maintenance too, so the integrations that were alerted are resolved
+		if success == 1 && previous != nil && len(previous.AlertIds) > 0 {
+			s.notifyRecovery(logger, url, previous)
+		}
+
+		if inMaintenance, _ := s.maintenance.check(url, now); inMaintenance {
+			logger.Debug("not alerting during maintenance", zap.Any("url", url))
+			continue
+		}
+
+		if startedFlapping {
+			logger.Debug("url started flapping", zap.Any("url", url))
+			s.raiseAlert(logger, url, model.AlertTypeFlapping, incident, now)
+			continue
+		}
+
+		// send alert if it has passed failure threshold. the count of the day is unchanged by a success
+		if failure == 1 && stat.FailureCount%url.Threshold == 0 && s.alerts.allowDown(url.Id, incident, now, policy) {
 			logger.Debug("creating alert", zap.Any("url", url), zap.Any("stat", stat))
-			err := s.dataStore.Alert().Add(context.Background(), &model.Alert{
-				UserId:   url.UserId,
-				UrlId:    url.Id,
-				Url:      url.Url,
-				IssuedAt: time.Now(),
-			})
-
-			if err != nil {
-				logger.Error("creating adding alert", zap.Error(err), zap.Any("url", url), zap.Any("stat", stat))
-				continue
-			}
+			s.raiseAlert(logger, url, model.AlertTypeDown, incident, now)
 		}
 	}
 
 	done <- 0
 }
+
+// reschedules the url by its failing policy after a check
+func (s *Scheduler) adapt(syncedHeap *util.SyncHeap[*TimedURL], url *TimedURL, success bool, now time.Time) {
+	if url == nil || url.failing == nil {
+		return
+	}
+
+	syncedHeap.Do(func(h util.CustomHeapInterface[*TimedURL]) {
+		// the url may have been removed by 'update' in the meantime
+		if url.observe(success, now) && url.index >= 0 {
+			heap.Fix(h, url.index)
+		}
+	})
+}
+
+// increments the buckets of all resolutions. coarser buckets are rolled up at the same time,
+// so finer buckets can expire after their retention without losing data
+func (s *Scheduler) addStats(logger *zap.Logger, r *Result, at time.Time, success, failure int) {
+	for _, resolution := range model.Resolutions {
+		stat := model.Stat{
+			Resolution:   resolution,
+			Start:        resolution.Truncate(at),
+			SuccessCount: success,
+			FailureCount: failure,
+		}
+
+		if retention := s.statsRetention[resolution]; retention > 0 {
+			stat.ExpiresAt = stat.Start.Add(resolution.Duration() + retention)
+		}
+
+		if _, err := s.dataStore.Stat().Add(context.Background(), r.Task.UserId, r.Task.UrlId, stat); err != nil {
+			logger.Error("error adding stat", zap.Error(err), zap.Any("result", r), zap.Any("stat", stat))
+		}
+	}
+}
+
+func (s *Scheduler) raiseAlert(logger *zap.Logger, url *model.URL, typ model.AlertType, incident *model.Incident, at time.Time) {
+	alert := &model.Alert{
+		UserId:   url.UserId,
+		UrlId:    url.Id,
+		Url:      url.Url,
+		IssuedAt: at,
+		Type:     typ,
+		Severity: model.SeverityOf(typ),
+		State:    model.AlertStateOpen,
+	}
+
+	alert.Tags = s.triggerTags(logger, url, incident)
+	if incident != nil {
+		alert.IncidentId = incident.Id
+	}
+
+	// silenced alerts are stored, so they are still counted for cooldowns and incidents
+	silence, err := findSilence(context.Background(), s.dataStore.Silence(), url, at)
+	if err != nil {
+		logger.Error("error checking silences", zap.Error(err), zap.Any("url", url))
+	} else if silence != nil {
+		logger.Debug("alert is silenced", zap.Any("url", url), zap.Any("silence", silence))
+		alert.SilencedBy = silence.Id
+	}
+
+	if err := s.dataStore.Alert().Add(context.Background(), alert); err != nil {
+		logger.Error("error adding alert", zap.Error(err), zap.Any("alert", alert))
+		return
+	}
+
+	s.alerts.alerted(url.Id, at)
+
+	if !alert.Silenced() {
+		if err := s.escalator.start(context.Background(), alert, url); err != nil {
+			logger.Error("error starting escalation", zap.Error(err), zap.Any("alert", alert))
+		}
+
+		if err := s.dispatcher.route(context.Background(), url, notification.NewMessage(alert, url, s.results.get(url.Id))); err != nil {
+			logger.Error("error routing alert", zap.Error(err), zap.Any("alert", alert))
+		}
+	}
+
+	if incident != nil {
+		s.incidents.addAlert(context.Background(), incident, alert)
+	}
+}
+
+// notifies the recovery of a url using the last alert of its resolved incident.
+// every channel that an alert of the incident was sent to is notified, so every trigger sent to an integration is resolved
+// returns the tags of the first alert of the incident, so the channels that label alerts by tags see one alert per incident.
+// the first alert of an incident has the current tags of the url
+func (s *Scheduler) triggerTags(logger *zap.Logger, url *model.URL, incident *model.Incident) []string {
+	if incident == nil || len(incident.AlertIds) == 0 {
+		return url.Tags
+	}
+
+	trigger, err := s.dataStore.Alert().Get(context.Background(), url.UserId, incident.AlertIds[0])
+	if err != nil {
+		logger.Error("error getting first alert of incident", zap.Error(err), zap.Any("incident", incident))
+		return url.Tags
+	}
+	return trigger.Tags
+}
+
+func (s *Scheduler) notifyRecovery(logger *zap.Logger, url *model.URL, incident *model.Incident) {
+	ctx := context.Background()
+
+	alert, err := s.dataStore.Alert().Get(ctx, url.UserId, incident.AlertIds[len(incident.AlertIds)-1])
+	if err != nil {
+		logger.Error("error getting last alert of incident", zap.Error(err), zap.Any("incident", incident))
+		return
+	}
+
+	msg := notification.NewMessage(alert, url, s.results.get(url.Id))
+	msg.Type = model.AlertTypeRecovered
+	msg.Severity = model.SeverityOf(model.AlertTypeRecovered)
+
+	if err := s.dispatcher.resolve(ctx, url, msg, incident.AlertIds); err != nil {
+		logger.Error("error routing recovery", zap.Error(err), zap.Any("url", url))
+	}
+}
@@ -0,0 +1,663 @@
+package monitoring
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/clock"
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	"github.com/MeysamBavi/http-monitoring/internal/util"
+	"go.uber.org/zap"
+)
+
+// the monitor starts one minute before midnight, so tests can cross a day
+var testStart = time.Date(2022, 10, 8, 23, 59, 0, 0, time.UTC)
+
+func newTestScheduler(clk clock.Clock) (*Scheduler, store.Store) {
+	dataStore := store.NewInMemoryStore(zap.NewNop())
+	cfg := Config{
+		NotificationTimeout:     time.Second,
+		NotificationWorkers:     1,
+		NotificationMaxAttempts: 1,
+	}
+	return newScheduler(zap.NewNop(), cfg, dataStore, clk), dataStore
+}
+
+func testUrl(id string, interval time.Duration) model.URL {
+	return model.URL{
+		Id:        model.ID(id),
+		UserId:    "1",
+		Url:       "https://example.com/" + id,
+		Threshold: 3,
+		Interval:  model.Interval{Duration: interval},
+	}
+}
+
+// runs 'schedule' until the test finishes
+func startTestSchedule(t *testing.T, s *Scheduler, syncedHeap *util.SyncHeap[*TimedURL]) <-chan *Task {
+	in := make(chan *Task)
+	shutdown := make(chan int)
+	go s.schedule(syncedHeap, in, shutdown)
+
+	t.Cleanup(func() {
+		shutdown <- 0
+		for range in {
+		}
+	})
+
+	return in
+}
+
+type scheduledCall struct {
+	at    time.Duration // since testStart
+	urlId model.ID
+}
+
+// advances the clock to each call and checks that its url is sent to workers
+func expectCalls(t *testing.T, clk *clock.Fake, in <-chan *Task, calls []scheduledCall) {
+	t.Helper()
+
+	for _, call := range calls {
+		// advance only when 'schedule' sleeps, so it does not compute its wait from a stale time
+		clk.WaitForTimers(1)
+		if wait := testStart.Add(call.at).Sub(clk.Now()); wait > 0 {
+			clk.Advance(wait)
+		}
+
+		select {
+		case task := <-in:
+			if task.UrlId != call.urlId {
+				t.Fatalf("expected url %v at %v, got %v", call.urlId, call.at, task.UrlId)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("url %v was not called at %v", call.urlId, call.at)
+		}
+	}
+
+	select {
+	case task := <-in:
+		t.Fatalf("unexpected call of url %v", task.UrlId)
+	default:
+	}
+}
+
+func TestScheduleOrder(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	s, _ := newTestScheduler(clk)
+
+	urls := make([]*TimedURL, 0)
+	for _, u := range []model.URL{testUrl("c", 45*time.Second), testUrl("a", 10*time.Second), testUrl("b", 25*time.Second)} {
+		urls = append(urls, NewTimedURL(u, clk.Now()))
+	}
+	in := startTestSchedule(t, s, util.NewSyncHeap[*TimedURL](NewHeap(urls...)))
+
+	expectCalls(t, clk, in, []scheduledCall{
+		{10 * time.Second, "a"},
+		{20 * time.Second, "a"},
+		{25 * time.Second, "b"},
+		{30 * time.Second, "a"},
+		{40 * time.Second, "a"},
+		{45 * time.Second, "c"},
+	})
+}
+
+func TestScheduleIntervalChange(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	s, _ := newTestScheduler(clk)
+
+	syncedHeap := util.NewSyncHeap[*TimedURL](NewHeap())
+	timedUrls := make(map[model.ID]*TimedURL)
+	in := startTestSchedule(t, s, syncedHeap)
+
+	s.apply(syncedHeap, timedUrls, store.UrlChangeEvent{Url: testUrl("a", 10*time.Second), Operation: store.UrlChangeOperationInsert})
+	s.apply(syncedHeap, timedUrls, store.UrlChangeEvent{Url: testUrl("b", 25*time.Second), Operation: store.UrlChangeOperationInsert})
+	expectCalls(t, clk, in, []scheduledCall{{10 * time.Second, "a"}})
+
+	// the new interval starts from the change, and 'schedule' is woken up to sleep until the new call time
+	clk.WaitForTimers(1)
+	clk.Advance(5 * time.Second)
+	s.apply(syncedHeap, timedUrls, store.UrlChangeEvent{Url: testUrl("a", time.Second), Operation: store.UrlChangeOperationUpdate})
+	expectCalls(t, clk, in, []scheduledCall{
+		{16 * time.Second, "a"},
+		{17 * time.Second, "a"},
+	})
+
+	paused := testUrl("a", time.Second)
+	paused.Paused = true
+	s.apply(syncedHeap, timedUrls, store.UrlChangeEvent{Url: paused, Operation: store.UrlChangeOperationUpdate})
+	s.apply(syncedHeap, timedUrls, store.UrlChangeEvent{Url: testUrl("c", time.Second), Operation: store.UrlChangeOperationInsert})
+	s.apply(syncedHeap, timedUrls, store.UrlChangeEvent{Url: testUrl("c", time.Second), Operation: store.UrlChangeOperationDelete})
+	expectCalls(t, clk, in, []scheduledCall{{25 * time.Second, "b"}})
+
+	if syncedHeap.Len() != 1 || len(timedUrls) != 1 {
+		t.Fatalf("paused and deleted urls should be removed, %d urls remain", syncedHeap.Len())
+	}
+}
+
+// heap.Init does not move urls that are already in order, so their indexes are only set by NewHeap
+func TestApplyRemovesFromInitializedHeap(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	s, _ := newTestScheduler(clk)
+
+	timedUrls := make(map[model.ID]*TimedURL)
+	urls := make([]*TimedURL, 0)
+	for i, id := range []model.ID{"a", "b", "c"} {
+		u := NewTimedURL(testUrl(string(id), time.Duration(i+1)*10*time.Second), clk.Now())
+		timedUrls[id] = u
+		urls = append(urls, u)
+	}
+	syncedHeap := util.NewSyncHeap[*TimedURL](NewHeap(urls...))
+
+	s.apply(syncedHeap, timedUrls, store.UrlChangeEvent{Url: testUrl("c", 30*time.Second), Operation: store.UrlChangeOperationDelete})
+
+	remaining := ""
+	syncedHeap.Do(func(h util.CustomHeapInterface[*TimedURL]) {
+		for h.Len() > 0 {
+			remaining += string(heap.Pop(h).(*TimedURL).UrlId)
+		}
+	})
+	if remaining != "ab" {
+		t.Fatalf("expected a and b to remain, got %q", remaining)
+	}
+}
+
+func TestInitializeHeapCatchUp(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	s, dataStore := newTestScheduler(clk)
+	s.catchUpWindow = time.Minute
+
+	checkedAt := func(u model.URL, ago time.Duration) model.URL {
+		at := testStart.Add(-ago)
+		u.LastCheckedAt = &at
+		return u
+	}
+	paused := testUrl("paused", time.Minute)
+	paused.Paused = true
+	urls := []model.URL{
+		checkedAt(testUrl("recent", time.Minute), 20*time.Second),
+		checkedAt(testUrl("overdue", time.Hour), 3*time.Hour),
+		testUrl("new", time.Minute),
+		checkedAt(testUrl("short", 10*time.Second), time.Minute),
+		paused,
+	}
+
+	ids := make(map[string]model.ID)
+	for i := range urls {
+		u := urls[i]
+		if err := dataStore.Url().Add(context.Background(), &u); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids[u.Url] = u.Id
+	}
+
+	syncedHeap, timedUrls := s.initializeHeap()
+	if syncedHeap.Len() != 4 || len(timedUrls) != 4 {
+		t.Fatalf("expected the 4 unpaused urls, got %d", syncedHeap.Len())
+	}
+
+	// the due urls are spread over the window by how long they have been overdue, but not beyond their interval
+	for name, want := range map[string]time.Duration{
+		"recent":  40 * time.Second,
+		"overdue": 0,
+		"short":   10 * time.Second,
+		"new":     40 * time.Second,
+	} {
+		got := timedUrls[ids["https://example.com/"+name]].callTime.Sub(testStart)
+		if got != want {
+			t.Errorf("%s: expected call at %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestScheduleJitter(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	s, _ := newTestScheduler(clk)
+	s.jitter = 1
+
+	syncedHeap := util.NewSyncHeap[*TimedURL](NewHeap())
+	timedUrls := make(map[model.ID]*TimedURL)
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		s.apply(syncedHeap, timedUrls, store.UrlChangeEvent{Url: testUrl(id, time.Minute), Operation: store.UrlChangeOperationInsert})
+	}
+
+	calls := make([]scheduledCall, 0)
+	for id, u := range timedUrls {
+		at := u.callTime.Sub(testStart)
+		if at <= 0 || at > time.Minute {
+			t.Fatalf("%v: first call at %v is not within its interval", id, at)
+		}
+		calls = append(calls, scheduledCall{at, id})
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].at < calls[j].at
+	})
+	for i := 1; i < len(calls); i++ {
+		if calls[i].at == calls[i-1].at {
+			t.Fatalf("urls %v and %v are called together", calls[i-1].urlId, calls[i].urlId)
+		}
+	}
+
+	// the offset depends only on the url, so a restart keeps it
+	restarted := NewTimedURL(testUrl("a", time.Minute), testStart.Add(17*time.Second)).withPhase(s.jitter)
+	if offset := restarted.callTime.Sub(timedUrls["a"].callTime) % time.Minute; offset != 0 {
+		t.Fatalf("the offset of the url changed by %v after a restart", offset)
+	}
+
+	// each url keeps its offset in the next intervals
+	first := calls
+	for _, call := range first {
+		calls = append(calls, scheduledCall{call.at + time.Minute, call.urlId})
+	}
+	in := startTestSchedule(t, s, syncedHeap)
+	expectCalls(t, clk, in, calls)
+}
+
+func TestScheduleCron(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	s, _ := newTestScheduler(clk)
+	s.jitter = 1
+
+	// 00:00 and 00:30 UTC. schedules are not offset by the jitter
+	url := testUrl("a", 0)
+	url.Schedule = &model.Schedule{Cron: []string{"0 6 * * *", "30 5 * * *"}, TimeZone: "Asia/Kolkata"}
+
+	runs := NextRuns(url, s.jitter, clk.Now(), 3)
+	if len(runs) != 3 || !runs[2].Equal(testStart.Add(24*time.Hour+time.Minute)) {
+		t.Fatalf("unexpected runs: %v", runs)
+	}
+
+	syncedHeap := util.NewSyncHeap[*TimedURL](NewHeap())
+	timedUrls := make(map[model.ID]*TimedURL)
+	in := startTestSchedule(t, s, syncedHeap)
+
+	s.apply(syncedHeap, timedUrls, store.UrlChangeEvent{Url: url, Operation: store.UrlChangeOperationInsert})
+	expectCalls(t, clk, in, []scheduledCall{
+		{time.Minute, "a"},
+		{31 * time.Minute, "a"},
+		{24*time.Hour + time.Minute, "a"},
+	})
+
+	// a schedule without runs is not scheduled
+	noRuns := testUrl("b", 0)
+	noRuns.Schedule = &model.Schedule{Cron: []string{"0 0 30 2 *"}}
+	s.apply(syncedHeap, timedUrls, store.UrlChangeEvent{Url: noRuns, Operation: store.UrlChangeOperationInsert})
+	if _, ok := timedUrls["b"]; ok {
+		t.Fatal("a url without runs should not be scheduled")
+	}
+}
+
+func TestScheduleFailingPolicy(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	s, _ := newTestScheduler(clk)
+
+	url := testUrl("a", time.Minute)
+	url.FailingPolicy = &model.FailingPolicy{
+		Interval:      model.Interval{Duration: 10 * time.Second},
+		SlowDownAfter: model.Interval{Duration: 30 * time.Second},
+		Backoff:       2,
+		MaxInterval:   model.Interval{Duration: 40 * time.Second},
+	}
+
+	syncedHeap := util.NewSyncHeap[*TimedURL](NewHeap())
+	timedUrls := make(map[model.ID]*TimedURL)
+	in := startTestSchedule(t, s, syncedHeap)
+	s.apply(syncedHeap, timedUrls, store.UrlChangeEvent{Url: url, Operation: store.UrlChangeOperationInsert})
+
+	// checked every 10s after the first failure, slowed down after 30s, and back to every minute after recovery
+	for _, call := range []struct {
+		at      time.Duration
+		success bool
+	}{
+		{60 * time.Second, false},
+		{70 * time.Second, false},
+		{80 * time.Second, false},
+		{90 * time.Second, false},
+		{110 * time.Second, false},
+		{150 * time.Second, false},
+		{190 * time.Second, true},
+		{250 * time.Second, true},
+	} {
+		expectCalls(t, clk, in, []scheduledCall{{call.at, "a"}})
+		s.adapt(syncedHeap, timedUrls["a"], call.success, clk.Now())
+	}
+}
+
+// the failing url is moved to its failing interval in a heap built by NewHeap, not only in one built by pushes
+func TestScheduleFailingPolicyInitializedHeap(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	s, _ := newTestScheduler(clk)
+
+	// b is a leaf under c, below a
+	failing := testUrl("b", 2*time.Minute)
+	failing.FailingPolicy = &model.FailingPolicy{Interval: model.Interval{Duration: 10 * time.Second}}
+	b := NewTimedURL(failing, clk.Now())
+	b.lastCall = clk.Now()
+	urls := []*TimedURL{
+		NewTimedURL(testUrl("a", time.Minute), clk.Now()),
+		NewTimedURL(testUrl("c", 70*time.Second), clk.Now()),
+		NewTimedURL(testUrl("d", 80*time.Second), clk.Now()),
+		b,
+	}
+
+	syncedHeap := util.NewSyncHeap[*TimedURL](NewHeap(urls...))
+	in := startTestSchedule(t, s, syncedHeap)
+
+	s.adapt(syncedHeap, b, false, clk.Now())
+	expectCalls(t, clk, in, []scheduledCall{{10 * time.Second, "b"}})
+}
+
+// sends the results to 'collect' and waits until they are written
+func collectResults(s *Scheduler, results ...*Result) {
+	out := make(chan *Result, len(results))
+	for _, r := range results {
+		out <- r
+	}
+	close(out)
+
+	done := make(chan int)
+	go s.collect(util.NewSyncHeap[*TimedURL](NewHeap()), out, done)
+	<-done
+}
+
+func resultOf(url model.URL, statusCode int) *Result {
+	return &Result{
+		Task:       &Task{UrlId: url.Id, URL: url.Url, UserId: url.UserId},
+		StatusCode: statusCode,
+	}
+}
+
+func TestCollectDayRollover(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	s, dataStore := newTestScheduler(clk)
+	ctx := context.Background()
+
+	url := testUrl("", time.Minute)
+	if err := dataStore.Url().Add(ctx, &url); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	today := model.DateOf(clk.Now())
+	collectResults(s, resultOf(url, 200), resultOf(url, 500))
+	clk.Advance(time.Minute)
+	tomorrow := model.DateOf(clk.Now())
+	collectResults(s, resultOf(url, 500))
+
+	if today == tomorrow {
+		t.Fatalf("the clock did not cross a day")
+	}
+
+	stats, err := dataStore.Url().GetDayStats(ctx, url.UserId, url.Id, func(model.Date) bool { return true })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[model.Date]model.DayStat{
+		today:    {Date: today, SuccessCount: 1, FailureCount: 1},
+		tomorrow: {Date: tomorrow, SuccessCount: 0, FailureCount: 1},
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("unexpected day stats: %v", stats)
+	}
+	for _, stat := range stats {
+		if stat != expected[stat.Date] {
+			t.Fatalf("unexpected day stat: %v", stat)
+		}
+	}
+
+	buckets, err := dataStore.Stat().Get(ctx, url.UserId, url.Id, model.ResolutionDay, today.Start(), tomorrow.Start().Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("unexpected day buckets: %v", buckets)
+	}
+}
+
+func TestUpdateRefreshesMaintenance(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	s, dataStore := newTestScheduler(clk)
+
+	url := testUrl("1", time.Minute)
+	window := &model.Maintenance{UserId: url.UserId, UrlId: url.Id, Start: testStart, End: testStart.Add(time.Hour)}
+	if err := dataStore.Maintenance().Add(context.Background(), window); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	shutdown := make(chan int)
+	done := make(chan int)
+	go s.update(util.NewSyncHeap[*TimedURL](NewHeap()), make(map[model.ID]*TimedURL), shutdown, done)
+	defer func() {
+		shutdown <- 0
+		<-done
+	}()
+
+	if active, _ := s.maintenance.check(&url, clk.Now()); active {
+		t.Fatal("the maintenance window was loaded before a refresh")
+	}
+
+	clk.WaitForTimers(1)
+	clk.Advance(maintenanceRefreshInterval)
+	for deadline := time.Now().Add(time.Second); ; {
+		if active, _ := s.maintenance.check(&url, clk.Now()); active {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the maintenance window was not loaded after the refresh interval")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// the next refresh is scheduled
+	clk.WaitForTimers(1)
+}
+
+func countAlerts(t *testing.T, dataStore store.Store, url model.URL) int {
+	t.Helper()
+
+	alerts, err := dataStore.Alert().GetByUserId(context.Background(), url.UserId, store.AlertFilter{UrlId: &url.Id}, nil, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return len(alerts)
+}
+
+func TestCollectAlertThreshold(t *testing.T) {
+	clk := clock.NewFake(testStart.Add(-time.Hour))
+	s, dataStore := newTestScheduler(clk)
+	s.alertPolicy = AlertPolicy{Cooldown: 10 * time.Minute}
+
+	url := testUrl("", time.Minute)
+	if err := dataStore.Url().Add(context.Background(), &url); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fail := func(n int) {
+		for i := 0; i < n; i++ {
+			collectResults(s, resultOf(url, 503))
+			clk.Advance(time.Minute)
+		}
+	}
+
+	fail(2)
+	if n := countAlerts(t, dataStore, url); n != 0 {
+		t.Fatalf("alerted before the threshold: %d alerts", n)
+	}
+
+	fail(1)
+	if n := countAlerts(t, dataStore, url); n != 1 {
+		t.Fatalf("expected an alert at the threshold, got %d alerts", n)
+	}
+
+	// the next multiple of the threshold is in the cooldown
+	fail(3)
+	if n := countAlerts(t, dataStore, url); n != 1 {
+		t.Fatalf("alerted in the cooldown: %d alerts", n)
+	}
+
+	clk.Advance(10 * time.Minute)
+	fail(3)
+	if n := countAlerts(t, dataStore, url); n != 2 {
+		t.Fatalf("expected an alert after the cooldown, got %d alerts", n)
+	}
+
+	// the failures of the day are still a multiple of the threshold, but a success is not alerted
+	clk.Advance(10 * time.Minute)
+	collectResults(s, resultOf(url, 200))
+	if n := countAlerts(t, dataStore, url); n != 2 {
+		t.Fatalf("alerted on a success: %d alerts", n)
+	}
+}
+
+func TestCollectNoResponse(t *testing.T) {
+	clk := clock.NewFake(testStart.Add(-time.Hour))
+	s, dataStore := newTestScheduler(clk)
+
+	url := testUrl("", time.Minute)
+	if err := dataStore.Url().Add(context.Background(), &url); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// checks without a response are failures, so they are alerted at the threshold
+	for i := 0; i < 3; i++ {
+		collectResults(s, resultOf(url, NoResponse))
+		clk.Advance(time.Minute)
+	}
+	if n := countAlerts(t, dataStore, url); n != 1 {
+		t.Fatalf("expected an alert at the threshold, got %d alerts", n)
+	}
+
+	// repeated checks without a response are not added to the timeline
+	incidents, err := dataStore.Incident().GetByUserId(context.Background(), url.UserId, store.IncidentFilter{UrlId: &url.Id})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(incidents) != 1 || len(incidents[0].Timeline) != 2 {
+		t.Fatalf("expected an incident with the opened and alert events only, got %v", incidents)
+	}
+}
+
+func TestCollectRecoveryResolvesAlertedChannels(t *testing.T) {
+	clk := clock.NewFake(testStart.Add(-time.Hour))
+	s, dataStore := newTestScheduler(clk)
+	ctx := context.Background()
+
+	url := testUrl("", time.Minute)
+	if err := dataStore.Url().Add(ctx, &url); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// only down alerts are routed to the channel, so the recovery is sent to it because the alert was
+	rule := &model.RoutingRule{UserId: url.UserId, Types: []model.AlertType{model.AlertTypeDown}, ChannelIds: []model.ID{"c"}}
+	if err := dataStore.RoutingRule().Add(ctx, rule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < url.Threshold; i++ {
+		collectResults(s, resultOf(url, 503))
+		clk.Advance(time.Minute)
+	}
+	collectResults(s, resultOf(url, 200))
+
+	jobs, err := dataStore.NotificationJob().GetByUserId(ctx, url.UserId, "", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	types := make(map[model.AlertType]model.ID)
+	for _, job := range jobs {
+		types[job.Type] = job.ChannelId
+	}
+	if len(jobs) != 2 || types[model.AlertTypeDown] != "c" || types[model.AlertTypeRecovered] != "c" {
+		t.Fatalf("unexpected notification jobs: %v", jobs)
+	}
+}
+
+func newBenchmarkScheduler() *Scheduler {
+	return &Scheduler{
+		logger:      zap.NewNop(),
+		maintenance: newMaintenanceCache(store.NewInMemoryStore(zap.NewNop()).Maintenance()),
+		clock:       clock.System,
+	}
+}
+
+// returns n urls that are due at callTime and are due again after interval
+func benchmarkUrls(n int, callTime time.Time, interval time.Duration) []*TimedURL {
+	urls := make([]*TimedURL, n)
+	for i := range urls {
+		urls[i] = &TimedURL{
+			UrlId:    model.ID(fmt.Sprint(i)),
+			URL:      "https://example.com",
+			Interval: interval,
+			callTime: callTime,
+		}
+	}
+	return urls
+}
+
+// runs 'schedule' until the benchmark finishes
+func startSchedule(b *testing.B, s *Scheduler, syncedHeap *util.SyncHeap[*TimedURL]) <-chan *Task {
+	in := make(chan *Task)
+	shutdown := make(chan int)
+	go s.schedule(syncedHeap, in, shutdown)
+
+	b.Cleanup(func() {
+		shutdown <- 0
+		for range in {
+		}
+	})
+
+	return in
+}
+
+// dispatch throughput when every url is always due
+func BenchmarkScheduleDispatch(b *testing.B) {
+	for _, n := range []int{1_000, 100_000} {
+		b.Run(fmt.Sprintf("urls=%d", n), func(b *testing.B) {
+			syncedHeap := util.NewSyncHeap[*TimedURL](NewHeap(benchmarkUrls(n, time.Now(), 0)...))
+			in := startSchedule(b, newBenchmarkScheduler(), syncedHeap)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				<-in
+			}
+		})
+	}
+}
+
+// latency between pushing a due url and receiving its task, while the scheduler sleeps until a url far in the future
+func BenchmarkScheduleWakeUp(b *testing.B) {
+	for _, n := range []int{0, 100_000} {
+		b.Run(fmt.Sprintf("urls=%d", n), func(b *testing.B) {
+			syncedHeap := util.NewSyncHeap[*TimedURL](NewHeap(benchmarkUrls(n, time.Now().Add(time.Hour), time.Hour)...))
+			in := startSchedule(b, newBenchmarkScheduler(), syncedHeap)
+			due := benchmarkUrls(b.N, time.Now(), time.Hour)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				syncedHeap.Push(due[i])
+				<-in
+			}
+		})
+	}
+}
+
+// cost of inserting and deleting urls that are not due, which wakes the scheduler to re-arm its timer
+func BenchmarkScheduleHeapChange(b *testing.B) {
+	syncedHeap := util.NewSyncHeap[*TimedURL](NewHeap(benchmarkUrls(100_000, time.Now().Add(time.Hour), time.Hour)...))
+	startSchedule(b, newBenchmarkScheduler(), syncedHeap)
+	urls := benchmarkUrls(b.N, time.Now().Add(time.Minute), time.Hour)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		url := urls[i]
+		syncedHeap.Push(url)
+		syncedHeap.Do(func(h util.CustomHeapInterface[*TimedURL]) {
+			heap.Remove(h, url.index)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+)
+
+// findSilence returns a silence of the url active at 'at', or nil if the url is not silenced.
+// silences are created and expired by the http server, so they are read on each alert
+func findSilence(ctx context.Context, silenceStore store.Silence, url *model.URL, at time.Time) (*model.Silence, error) {
+	silences, err := silenceStore.GetActive(ctx, url.UserId, at)
+	if err != nil {
+		return nil, fmt.Errorf("could not get active silences: %w", err)
+	}
+
+	for _, s := range silences {
+		if s.Matches(url) {
+			return s, nil
+		}
+	}
+
+	return nil, nil
+}
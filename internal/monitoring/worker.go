@@ -5,7 +5,6 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -22,16 +21,6 @@ func NewWorker(requestTimeout time.Duration, logger *zap.Logger) *Worker {
 	}
 }
 
-func (w *Worker) Work(wg *sync.WaitGroup, in <-chan *Task, out chan<- *Result) {
-	for t := range in {
-		r, ok := w.Process(t)
-		if ok {
-			out <- r
-		}
-	}
-	wg.Done()
-}
-
 func (w *Worker) Process(t *Task) (*Result, bool) {
 
 	// creating http request
@@ -48,9 +37,14 @@ func (w *Worker) Process(t *Task) (*Result, bool) {
 	// sending http request
 	res, errRes := http.DefaultClient.Do(req)
 
+	// the url did not respond in time or refused the connection, so the check fails with no status code
 	if errRes != nil {
-		w.logger.Error("error sending the request", zap.Error(errRes))
-		return nil, false
+		w.logger.Info("error sending the request", zap.Error(errRes))
+		return &Result{
+			Task:       t,
+			StatusCode: NoResponse,
+			Body:       errRes.Error(),
+		}, true
 	}
 
 	// reading response body
@@ -0,0 +1,28 @@
+package monitoring
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestWorkerNoResponse(t *testing.T) {
+	// the server is closed, so the connection is refused
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	w := NewWorker(time.Second, zap.NewNop())
+	r, ok := w.Process(&Task{UrlId: "a", URL: server.URL})
+	if !ok {
+		t.Fatal("a check without a response should have a result")
+	}
+	if r.StatusCode != NoResponse {
+		t.Fatalf("expected status code %d, got %d", NoResponse, r.StatusCode)
+	}
+	if r.Body == "" {
+		t.Fatal("expected the error of the request in the body")
+	}
+}
@@ -0,0 +1,108 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// AlertmanagerRefreshInterval is how often the firing alerts of open incidents are sent to alertmanager again.
+// a firing alert ends after alertmanagerFiringTTL, so alertmanager does not resolve it while the incident is open
+const AlertmanagerRefreshInterval = time.Minute
+
+// a firing alert ends after missing a few refreshes, e.g. if the monitor is stopped
+const alertmanagerFiringTTL = 3 * AlertmanagerRefreshInterval
+
+// AlertmanagerSender posts the message to the /api/v2/alerts endpoint of an alertmanager.
+// there is one alertmanager alert per incident of a url, so a recovery resolves the alert whatever type it was raised with.
+// its labels are made from the tags the incident was first alerted with, so a tag change does not split the alert
+type AlertmanagerSender struct {
+	client *http.Client
+	links  links
+}
+
+type alertmanagerAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     string            `json:"startsAt"`
+	EndsAt       string            `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL"`
+}
+
+func (s *AlertmanagerSender) Send(ctx context.Context, channel *model.Channel, msg *Message, text string) error {
+	tags := msg.Alert.Tags
+	if tags == nil && msg.Url != nil {
+		// alerts raised before their tags were kept
+		tags = msg.Url.Tags
+	}
+
+	alert := alertmanagerAlert{
+		Labels: LabelsOf(msg.Alert, tags),
+		Annotations: map[string]string{
+			"summary":     titleOf(msg),
+			"description": text,
+			"type":        string(msg.Type),
+			"severity":    string(msg.Severity),
+		},
+		StartsAt:     msg.Alert.IssuedAt.UTC().Format(time.RFC3339),
+		GeneratorURL: s.links.stats(msg),
+	}
+	alert.Labels["alertname"] = "UrlIncident"
+	alert.Labels["dedup_key"] = DedupKeyOf(msg.Alert)
+
+	if resolves(msg) {
+		alert.EndsAt = time.Now().UTC().Format(time.RFC3339)
+	} else {
+		alert.EndsAt = time.Now().Add(alertmanagerFiringTTL).UTC().Format(time.RFC3339)
+	}
+
+	body, err := json.Marshal([]alertmanagerAlert{alert})
+	if err != nil {
+		return fmt.Errorf("could not marshal alert: %w", err)
+	}
+
+	return postJSON(ctx, s.client, strings.TrimRight(channel.Url, "/")+"/api/v2/alerts", body)
+}
+
+// RefiresWhileOpen reports whether the firing alerts are sent to channels of the type again while their incident is open,
+// every AlertmanagerRefreshInterval, because the channel ends the alerts that are not sent again
+func RefiresWhileOpen(channelType model.ChannelType) bool {
+	return channelType == model.ChannelTypeAlertmanager
+}
+
+// LabelsOf returns the alertmanager labels of the alert with the tags. a tag like "env=prod" or "env:prod" becomes the label
+// tag_env="prod", any other tag becomes tag_<tag>="true". characters that are not allowed in label names are replaced with '_'
+func LabelsOf(alert *model.Alert, tags []string) map[string]string {
+	labels := map[string]string{
+		"url_id": string(alert.UrlId),
+		"url":    alert.Url,
+	}
+
+	for _, tag := range tags {
+		name, value, ok := strings.Cut(tag, "=")
+		if !ok {
+			name, value, ok = strings.Cut(tag, ":")
+		}
+		if !ok {
+			value = "true"
+		}
+		labels["tag_"+labelName(name)] = value
+	}
+
+	return labels
+}
+
+// label names must match [a-zA-Z_][a-zA-Z0-9_]*. the name is prefixed, so it can start with a digit
+func labelName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// links builds the urls that chat messages link back to
+type links struct {
+	publicUrl string
+}
+
+func linker(publicUrl string) links {
+	return links{publicUrl: strings.TrimRight(publicUrl, "/")}
+}
+
+// stats returns the link to the stats of the url of the message
+func (l links) stats(msg *Message) string {
+	return fmt.Sprintf("%s/urls/%s/stats", l.publicUrl, msg.Url.Id)
+}
+
+// titleOf returns the one line summary chat messages are headed with
+func titleOf(msg *Message) string {
+	return fmt.Sprintf("%s: %s", strings.ToUpper(string(msg.Type)), msg.Url.Url)
+}
+
+// a field shown next to the text of a chat message
+type fact struct {
+	name  string
+	value string
+}
+
+// factsOf returns the details of the message shown by every chat platform
+func factsOf(msg *Message) []fact {
+	facts := []fact{
+		{name: "Severity", value: string(msg.Severity)},
+		{name: "Type", value: string(msg.Type)},
+	}
+	if msg.Result != nil {
+		facts = append(facts, fact{name: "Status code", value: fmt.Sprint(msg.Result.StatusCode)})
+	}
+	if len(msg.Url.Tags) > 0 {
+		facts = append(facts, fact{name: "Tags", value: strings.Join(msg.Url.Tags, ", ")})
+	}
+	return facts
+}
+
+// colorOf returns the rgb color of the severity of the message
+func colorOf(msg *Message) int {
+	if msg.Type == model.AlertTypeRecovered {
+		return 0x2eb67d
+	}
+
+	switch msg.Severity {
+	case model.AlertSeverityCritical:
+		return 0xe01e5a
+	case model.AlertSeverityWarning:
+		return 0xecb22e
+	default:
+		return 0x36c5f0
+	}
+}
+
+func hexColor(color int) string {
+	return fmt.Sprintf("#%06x", color)
+}
@@ -0,0 +1,158 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
+	"go.uber.org/zap"
+)
+
+const publicUrl = "https://httpm.example.com"
+
+// a fake webhook server that records the bodies it receives
+func fakeWebhook(t *testing.T, handle func(w http.ResponseWriter, attempt int)) (*httptest.Server, *[]map[string]any) {
+	var bodies []map[string]any
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("unexpected content type: %q", ct)
+		}
+
+		raw, _ := io.ReadAll(r.Body)
+		body := make(map[string]any)
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("invalid json body: %v", err)
+		}
+		bodies = append(bodies, body)
+
+		handle(w, int(atomic.AddInt32(&attempts, 1)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &bodies
+}
+
+func ok(w http.ResponseWriter, _ int) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestChatPayloads(t *testing.T) {
+	msg := notification.SampleMessage(time.Date(2022, 10, 8, 12, 0, 0, 0, time.UTC))
+	statsLink := publicUrl + "/urls/" + string(msg.Url.Id) + "/stats"
+
+	// the path of the stats link in the payload of each channel type
+	paths := map[model.ChannelType][]any{
+		model.ChannelTypeSlack:      {"blocks", 2, "elements", 0, "url"},
+		model.ChannelTypeDiscord:    {"embeds", 0, "url"},
+		model.ChannelTypeMattermost: {"attachments", 0, "title_link"},
+		model.ChannelTypeTeams:      {"attachments", 0, "content", "actions", 0, "url"},
+	}
+
+	notifier := notification.NewNotifier(zap.NewNop(), time.Second, publicUrl+"/")
+	for typ, path := range paths {
+		server, bodies := fakeWebhook(t, ok)
+		channel := &model.Channel{Id: "c", Type: typ, Url: server.URL}
+
+		if err := notifier.Notify(context.Background(), channel, msg); err != nil {
+			t.Fatalf("%s: unexpected error: %v", typ, err)
+		}
+
+		if len(*bodies) != 1 {
+			t.Fatalf("%s: expected 1 request, got %d", typ, len(*bodies))
+		}
+
+		if link := lookup((*bodies)[0], path); link != statsLink {
+			t.Fatalf("%s: unexpected stats link: %v", typ, link)
+		}
+
+		raw, _ := json.Marshal((*bodies)[0])
+		if !strings.Contains(string(raw), "https://example.com/health is down") {
+			t.Fatalf("%s: rendered text is missing: %s", typ, raw)
+		}
+	}
+}
+
+func lookup(v any, path []any) any {
+	for _, key := range path {
+		switch k := key.(type) {
+		case string:
+			m, _ := v.(map[string]any)
+			v = m[k]
+		case int:
+			s, _ := v.([]any)
+			if k >= len(s) {
+				return nil
+			}
+			v = s[k]
+		}
+	}
+	return v
+}
+
+func TestRateLimitedRequestsAreRetried(t *testing.T) {
+	server, bodies := fakeWebhook(t, func(w http.ResponseWriter, attempt int) {
+		if attempt == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	notifier := notification.NewNotifier(zap.NewNop(), time.Second, publicUrl)
+	channel := &model.Channel{Id: "c", Type: model.ChannelTypeSlack, Url: server.URL}
+	if err := notifier.Notify(context.Background(), channel, notification.SampleMessage(time.Now())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(*bodies) != 2 {
+		t.Fatalf("expected the request to be retried once, got %d requests", len(*bodies))
+	}
+}
+
+func TestLongRetryAfterIsReturned(t *testing.T) {
+	server, bodies := fakeWebhook(t, func(w http.ResponseWriter, _ int) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	notifier := notification.NewNotifier(zap.NewNop(), time.Second, publicUrl)
+	channel := &model.Channel{Id: "c", Type: model.ChannelTypeDiscord, Url: server.URL}
+	err := notifier.Notify(context.Background(), channel, notification.SampleMessage(time.Now()))
+
+	var rateLimited *notification.RateLimitedError
+	if !errors.As(err, &rateLimited) {
+		t.Fatalf("expected a rate limited error, got %v", err)
+	}
+
+	if rateLimited.RetryAfter != 2*time.Minute {
+		t.Fatalf("unexpected retry after: %v", rateLimited.RetryAfter)
+	}
+
+	if len(*bodies) != 1 {
+		t.Fatalf("the request should not be retried, got %d requests", len(*bodies))
+	}
+}
+
+func TestFailedRequestsAreReported(t *testing.T) {
+	server, _ := fakeWebhook(t, func(w http.ResponseWriter, _ int) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	notifier := notification.NewNotifier(zap.NewNop(), time.Second, publicUrl)
+	channel := &model.Channel{Id: "c", Type: model.ChannelTypeTeams, Url: server.URL}
+	if err := notifier.Notify(context.Background(), channel, notification.SampleMessage(time.Now())); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
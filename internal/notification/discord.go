@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// DiscordSender posts the message as an embed to a discord webhook
+type DiscordSender struct {
+	client *http.Client
+	links  links
+}
+
+type discordPayload struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Url         string         `json:"url"`
+	Color       int            `json:"color"`
+	Timestamp   string         `json:"timestamp"`
+	Fields      []discordField `json:"fields"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func (s *DiscordSender) Send(ctx context.Context, channel *model.Channel, msg *Message, text string) error {
+	fields := make([]discordField, 0)
+	for _, f := range factsOf(msg) {
+		fields = append(fields, discordField{Name: f.name, Value: f.value, Inline: true})
+	}
+
+	payload := discordPayload{
+		Embeds: []discordEmbed{{
+			Title:       titleOf(msg),
+			Description: text,
+			Url:         s.links.stats(msg),
+			Color:       colorOf(msg),
+			Timestamp:   msg.Alert.IssuedAt.UTC().Format(time.RFC3339),
+			Fields:      fields,
+		}},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not marshal message: %w", err)
+	}
+
+	return postJSON(ctx, s.client, channel.Url, body)
+}
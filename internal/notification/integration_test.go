@@ -0,0 +1,204 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
+	"go.uber.org/zap"
+)
+
+// a fake integration that records the paths and bodies of the requests it receives
+func fakeIntegration(t *testing.T) (*httptest.Server, *[]string, *[]json.RawMessage) {
+	var paths []string
+	var bodies []json.RawMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		paths = append(paths, r.URL.Path)
+		bodies = append(bodies, raw)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &paths, &bodies
+}
+
+func recoveryOf(msg *notification.Message) *notification.Message {
+	return followUpOf(msg, model.AlertTypeRecovered)
+}
+
+func followUpOf(msg *notification.Message, typ model.AlertType) *notification.Message {
+	followUp := *msg
+	followUp.Type = typ
+	followUp.Severity = model.SeverityOf(typ)
+	return &followUp
+}
+
+func TestPagerDutyTriggerAndResolve(t *testing.T) {
+	server, _, bodies := fakeIntegration(t)
+	notifier := notification.NewNotifier(zap.NewNop(), time.Second, publicUrl)
+	channel := &model.Channel{Id: "c", Type: model.ChannelTypePagerDuty, Url: server.URL, RoutingKey: "key"}
+
+	msg := notification.SampleMessage(time.Now())
+	for _, m := range []*notification.Message{msg, recoveryOf(msg)} {
+		if err := notifier.Notify(context.Background(), channel, m); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	type event struct {
+		RoutingKey  string          `json:"routing_key"`
+		EventAction string          `json:"event_action"`
+		DedupKey    string          `json:"dedup_key"`
+		Payload     *map[string]any `json:"payload"`
+	}
+
+	var trigger, resolve event
+	_ = json.Unmarshal((*bodies)[0], &trigger)
+	_ = json.Unmarshal((*bodies)[1], &resolve)
+
+	if trigger.EventAction != "trigger" || resolve.EventAction != "resolve" {
+		t.Fatalf("unexpected actions: %q, %q", trigger.EventAction, resolve.EventAction)
+	}
+
+	if trigger.RoutingKey != "key" || resolve.RoutingKey != "key" {
+		t.Fatalf("routing key was not sent")
+	}
+
+	if trigger.DedupKey == "" || trigger.DedupKey != resolve.DedupKey {
+		t.Fatalf("dedup keys do not match: %q, %q", trigger.DedupKey, resolve.DedupKey)
+	}
+
+	if trigger.Payload == nil || (*trigger.Payload)["severity"] != "critical" {
+		t.Fatalf("unexpected trigger payload: %v", trigger.Payload)
+	}
+
+	// a user acknowledges and resolves the alert
+	for _, typ := range []model.AlertType{model.AlertTypeAcknowledged, model.AlertTypeResolved} {
+		if err := notifier.Notify(context.Background(), channel, followUpOf(msg, typ)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var acknowledge, manualResolve event
+	_ = json.Unmarshal((*bodies)[2], &acknowledge)
+	_ = json.Unmarshal((*bodies)[3], &manualResolve)
+
+	if acknowledge.EventAction != "acknowledge" || manualResolve.EventAction != "resolve" {
+		t.Fatalf("unexpected actions of state changes: %q, %q", acknowledge.EventAction, manualResolve.EventAction)
+	}
+
+	if acknowledge.DedupKey != trigger.DedupKey || acknowledge.Payload != nil {
+		t.Fatalf("unexpected acknowledge event: %+v", acknowledge)
+	}
+
+	// another incident of the url is a different pagerduty incident
+	other := *msg.Alert
+	other.IncidentId = "507f191e810c19729de860ec"
+	if notification.DedupKeyOf(&other) == trigger.DedupKey {
+		t.Fatalf("incidents share a dedup key")
+	}
+}
+
+func TestPagerDutySummaryTruncation(t *testing.T) {
+	server, _, bodies := fakeIntegration(t)
+	notifier := notification.NewNotifier(zap.NewNop(), time.Second, publicUrl)
+	// a 2 byte rune is cut by the byte limit of the summary
+	channel := &model.Channel{Id: "c", Type: model.ChannelTypePagerDuty, Url: server.URL, RoutingKey: "key",
+		Template: &model.Template{Format: model.TemplateFormatText, Body: strings.Repeat("é", 600)}}
+
+	if err := notifier.Notify(context.Background(), channel, notification.SampleMessage(time.Now())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var event struct {
+		Payload struct {
+			Summary string `json:"summary"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal((*bodies)[0], &event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	summary := event.Payload.Summary
+	if len(summary) > 1024 || !strings.HasSuffix(summary, "...") || strings.ContainsRune(summary, utf8.RuneError) {
+		t.Fatalf("unexpected summary of %d bytes: %q", len(summary), summary)
+	}
+}
+
+func TestAlertmanagerAlerts(t *testing.T) {
+	server, paths, bodies := fakeIntegration(t)
+	notifier := notification.NewNotifier(zap.NewNop(), time.Second, publicUrl)
+	channel := &model.Channel{Id: "c", Type: model.ChannelTypeAlertmanager, Url: server.URL + "/"}
+
+	msg := notification.SampleMessage(time.Now())
+	msg.Alert.Tags = []string{"production", "env=prod", "team:core-api"}
+
+	// the tags of the url changed during the incident, but the alert keeps the tags it was raised with
+	recovery := recoveryOf(msg)
+	changed := *msg.Url
+	changed.Tags = []string{"staging"}
+	recovery.Url = &changed
+
+	for _, m := range []*notification.Message{msg, recovery} {
+		if err := notifier.Notify(context.Background(), channel, m); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for _, path := range *paths {
+		if path != "/api/v2/alerts" {
+			t.Fatalf("unexpected path: %q", path)
+		}
+	}
+
+	type alert struct {
+		Labels map[string]string `json:"labels"`
+		EndsAt string            `json:"endsAt"`
+	}
+
+	var fired, resolved []alert
+	_ = json.Unmarshal((*bodies)[0], &fired)
+	_ = json.Unmarshal((*bodies)[1], &resolved)
+
+	expected := map[string]string{
+		"tag_production": "true",
+		"tag_env":        "prod",
+		"tag_team":       "core-api",
+		"url_id":         string(msg.Url.Id),
+	}
+	for name, value := range expected {
+		if fired[0].Labels[name] != value {
+			t.Fatalf("unexpected label %s: %q", name, fired[0].Labels[name])
+		}
+	}
+
+	// the firing alert ends after it misses a few refreshes, and the resolved alert ends now
+	firedEnd, err := time.Parse(time.RFC3339, fired[0].EndsAt)
+	if err != nil || !firedEnd.After(time.Now().Add(notification.AlertmanagerRefreshInterval)) {
+		t.Fatalf("the firing alert should end after the next refresh: %q", fired[0].EndsAt)
+	}
+	resolvedEnd, err := time.Parse(time.RFC3339, resolved[0].EndsAt)
+	if err != nil || resolvedEnd.After(time.Now()) {
+		t.Fatalf("the resolved alert should end now: %q", resolved[0].EndsAt)
+	}
+
+	// alertmanager resolves the alert with the same labels
+	if len(fired[0].Labels) != len(resolved[0].Labels) {
+		t.Fatalf("labels differ: %v, %v", fired[0].Labels, resolved[0].Labels)
+	}
+	for name, value := range fired[0].Labels {
+		if resolved[0].Labels[name] != value {
+			t.Fatalf("labels differ: %v, %v", fired[0].Labels, resolved[0].Labels)
+		}
+	}
+}
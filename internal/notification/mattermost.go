@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// MattermostSender posts the message as an attachment to a mattermost incoming webhook
+type MattermostSender struct {
+	client *http.Client
+	links  links
+}
+
+type mattermostPayload struct {
+	Attachments []mattermostAttachment `json:"attachments"`
+}
+
+type mattermostAttachment struct {
+	// Fallback is shown in notifications of clients that do not render attachments
+	Fallback  string            `json:"fallback"`
+	Color     string            `json:"color"`
+	Title     string            `json:"title"`
+	TitleLink string            `json:"title_link"`
+	Text      string            `json:"text"`
+	Fields    []mattermostField `json:"fields"`
+}
+
+type mattermostField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func (s *MattermostSender) Send(ctx context.Context, channel *model.Channel, msg *Message, text string) error {
+	fields := make([]mattermostField, 0)
+	for _, f := range factsOf(msg) {
+		fields = append(fields, mattermostField{Title: f.name, Value: f.value, Short: true})
+	}
+
+	payload := mattermostPayload{
+		Attachments: []mattermostAttachment{{
+			Fallback:  text,
+			Color:     hexColor(colorOf(msg)),
+			Title:     titleOf(msg),
+			TitleLink: s.links.stats(msg),
+			Text:      text,
+			Fields:    fields,
+		}},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not marshal message: %w", err)
+	}
+
+	return postJSON(ctx, s.client, channel.Url, body)
+}
@@ -0,0 +1,86 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"go.uber.org/zap"
+)
+
+// Message is what is sent to a channel about an alert. it is also the data templates are executed with
+type Message struct {
+	Type     model.AlertType     `json:"type" description:"alert type, recovered when the url of the alert recovers, or acknowledged or resolved when a user changes the state of the alert"`
+	Severity model.AlertSeverity `json:"severity"`
+	Alert    *model.Alert        `json:"alert"`
+	Url      *model.URL          `json:"url"`
+	// Result is the latest check of the url. it is nil if the monitor has not checked the url since it started
+	Result *CheckResult `json:"result,omitempty" description:"latest check of the url, empty if it is unknown"`
+}
+
+// CheckResult is the result of checking a url
+type CheckResult = model.CheckResult
+
+// NewMessage returns the message of an alert of the url
+func NewMessage(alert *model.Alert, url *model.URL, result *CheckResult) *Message {
+	return &Message{
+		Type:     alert.CurrentType(),
+		Severity: alert.CurrentSeverity(),
+		Alert:    alert,
+		Url:      url,
+		Result:   result,
+	}
+}
+
+// Sender delivers messages to one type of channel. text is the message rendered by the template of the channel
+type Sender interface {
+	Send(ctx context.Context, channel *model.Channel, msg *Message, text string) error
+}
+
+// Notifier sends messages to channels of any supported type
+type Notifier struct {
+	logger  *zap.Logger
+	senders map[model.ChannelType]Sender
+}
+
+// NewNotifier returns a notifier whose requests time out after timeout. messages link to the stats of the url under publicUrl
+func NewNotifier(logger *zap.Logger, timeout time.Duration, publicUrl string) *Notifier {
+	client := &http.Client{Timeout: timeout}
+	links := linker(publicUrl)
+	return &Notifier{
+		logger: logger,
+		senders: map[model.ChannelType]Sender{
+			model.ChannelTypeWebhook:      &WebhookSender{client: client},
+			model.ChannelTypeSlack:        &SlackSender{client: client, links: links},
+			model.ChannelTypeDiscord:      &DiscordSender{client: client, links: links},
+			model.ChannelTypeMattermost:   &MattermostSender{client: client, links: links},
+			model.ChannelTypeTeams:        &TeamsSender{client: client, links: links},
+			model.ChannelTypePagerDuty:    &PagerDutySender{client: client, links: links},
+			model.ChannelTypeAlertmanager: &AlertmanagerSender{client: client, links: links},
+		},
+	}
+}
+
+func (n *Notifier) Notify(ctx context.Context, channel *model.Channel, msg *Message) error {
+	sender, ok := n.senders[channel.Type]
+	if !ok {
+		return fmt.Errorf("unsupported channel type %q", channel.Type)
+	}
+
+	text, err := Render(TemplateOf(channel), msg)
+	if err != nil {
+		// a broken override must not lose the notification
+		n.logger.Warn("could not render channel template, using the default", zap.Error(err), zap.Any("channel_id", channel.Id))
+		if text, err = Render(DefaultTemplate(channel.Type), msg); err != nil {
+			return fmt.Errorf("could not render default template: %w", err)
+		}
+	}
+
+	if err := sender.Send(ctx, channel, msg, text); err != nil {
+		return fmt.Errorf("could not notify channel %v: %w", channel.Id, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// the summary of pagerduty events is limited to 1024 characters
+const maxPagerDutySummary = 1024
+
+// PagerDutySender sends the message as an event of the pagerduty events v2 api. alerts trigger an incident,
+// recoveries and manual resolutions resolve it, and acknowledgements acknowledge it. the events of an incident of a url share a dedup key, so they are grouped by pagerduty
+type PagerDutySender struct {
+	client *http.Client
+	links  links
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Client      string            `json:"client,omitempty"`
+	ClientUrl   string            `json:"client_url,omitempty"`
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp"`
+	Class         string         `json:"class"`
+	CustomDetails map[string]any `json:"custom_details"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+func (s *PagerDutySender) Send(ctx context.Context, channel *model.Channel, msg *Message, text string) error {
+	event := pagerDutyEvent{
+		RoutingKey: channel.RoutingKey,
+		DedupKey:   DedupKeyOf(msg.Alert),
+	}
+
+	switch {
+	case resolves(msg):
+		event.EventAction = "resolve"
+	case msg.Type == model.AlertTypeAcknowledged:
+		event.EventAction = "acknowledge"
+	default:
+		event.EventAction = "trigger"
+		event.Client = "http-monitoring"
+		event.ClientUrl = s.links.stats(msg)
+		event.Links = []pagerDutyLink{{Href: s.links.stats(msg), Text: "View stats"}}
+		event.Payload = &pagerDutyPayload{
+			Summary:   truncate(text, maxPagerDutySummary),
+			Source:    msg.Url.Url,
+			Severity:  string(msg.Severity),
+			Timestamp: msg.Alert.IssuedAt.UTC().Format(time.RFC3339),
+			Class:     string(msg.Type),
+			CustomDetails: map[string]any{
+				"url_id": msg.Url.Id,
+				"tags":   msg.Url.Tags,
+				"result": msg.Result,
+			},
+		}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("could not marshal event: %w", err)
+	}
+
+	return postJSON(ctx, s.client, channel.Url, body)
+}
+
+// DedupKeyOf returns the key shared by the events of the incident of the alert
+func DedupKeyOf(alert *model.Alert) string {
+	if alert.IncidentId == "" {
+		// alerts raised before incidents were tracked do not have one
+		return fmt.Sprintf("httpm/%s/alert/%s", alert.UrlId, alert.Id)
+	}
+	return fmt.Sprintf("httpm/%s/incident/%s", alert.UrlId, alert.IncidentId)
+}
+
+// resolves reports whether the message ends the incident of its alert
+func resolves(msg *Message) bool {
+	return msg.Type == model.AlertTypeRecovered || msg.Type == model.AlertTypeResolved
+}
+
+// FollowsStateChange reports whether channels of the type are notified when a user changes the state of an alert
+// to typ, because they track the incident of the alert. alertmanager has no acknowledgements
+func FollowsStateChange(channelType model.ChannelType, typ model.AlertType) bool {
+	switch channelType {
+	case model.ChannelTypePagerDuty:
+		return typ == model.AlertTypeAcknowledged || typ == model.AlertTypeResolved
+	case model.ChannelTypeAlertmanager:
+		return typ == model.AlertTypeResolved
+	default:
+		return false
+	}
+}
+
+// truncates s to at most n bytes. it is cut at the start of a rune, so the result is valid utf-8
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	end := n - 3
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end] + "..."
+}
@@ -0,0 +1,67 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// SlackSender posts the message as blocks to a slack incoming webhook
+type SlackSender struct {
+	client *http.Client
+	links  links
+}
+
+type slackPayload struct {
+	// Text is shown in notifications of clients that do not render blocks
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string         `json:"type"`
+	Text     *slackText     `json:"text,omitempty"`
+	Fields   []*slackText   `json:"fields,omitempty"`
+	Elements []slackElement `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackElement struct {
+	Type string     `json:"type"`
+	Text *slackText `json:"text"`
+	Url  string     `json:"url"`
+}
+
+func (s *SlackSender) Send(ctx context.Context, channel *model.Channel, msg *Message, text string) error {
+	fields := make([]*slackText, 0)
+	for _, f := range factsOf(msg) {
+		fields = append(fields, &slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", f.name, f.value)})
+	}
+
+	payload := slackPayload{
+		Text: text,
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: titleOf(msg)}},
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}, Fields: fields},
+			{Type: "actions", Elements: []slackElement{{
+				Type: "button",
+				Text: &slackText{Type: "plain_text", Text: "View stats"},
+				Url:  s.links.stats(msg),
+			}}},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not marshal message: %w", err)
+	}
+
+	return postJSON(ctx, s.client, channel.Url, body)
+}
@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// TeamsSender posts the message as an adaptive card to a microsoft teams incoming webhook
+type TeamsSender struct {
+	client *http.Client
+	links  links
+}
+
+type teamsPayload struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []teamsElement `json:"body"`
+	Actions []teamsAction  `json:"actions"`
+}
+
+type teamsElement struct {
+	Type   string      `json:"type"`
+	Text   string      `json:"text,omitempty"`
+	Weight string      `json:"weight,omitempty"`
+	Size   string      `json:"size,omitempty"`
+	Color  string      `json:"color,omitempty"`
+	Wrap   bool        `json:"wrap,omitempty"`
+	Facts  []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type teamsAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Url   string `json:"url"`
+}
+
+func (s *TeamsSender) Send(ctx context.Context, channel *model.Channel, msg *Message, text string) error {
+	facts := make([]teamsFact, 0)
+	for _, f := range factsOf(msg) {
+		facts = append(facts, teamsFact{Title: f.name, Value: f.value})
+	}
+
+	card := teamsCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []teamsElement{
+			{Type: "TextBlock", Text: titleOf(msg), Weight: "Bolder", Size: "Medium", Color: teamsColorOf(msg), Wrap: true},
+			{Type: "TextBlock", Text: text, Wrap: true},
+			{Type: "FactSet", Facts: facts},
+		},
+		Actions: []teamsAction{{Type: "Action.OpenUrl", Title: "View stats", Url: s.links.stats(msg)}},
+	}
+
+	payload := teamsPayload{
+		Type:        "message",
+		Attachments: []teamsAttachment{{ContentType: "application/vnd.microsoft.card.adaptive", Content: card}},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not marshal message: %w", err)
+	}
+
+	return postJSON(ctx, s.client, channel.Url, body)
+}
+
+// adaptive cards only support named colors
+func teamsColorOf(msg *Message) string {
+	if msg.Type == model.AlertTypeRecovered {
+		return "Good"
+	}
+
+	switch msg.Severity {
+	case model.AlertSeverityCritical:
+		return "Attention"
+	case model.AlertSeverityWarning:
+		return "Warning"
+	default:
+		return "Accent"
+	}
+}
@@ -0,0 +1,154 @@
+package notification
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// maxRenderedSize limits the size of a rendered message
+const maxRenderedSize = 16 * 1024
+
+const defaultTextTemplate = `[{{.Severity | upper}}] {{.Url.Url}} is {{.Type}}
+{{- with .Result}} (status code {{.StatusCode}} at {{formatTime .CheckedAt}}){{end}}`
+
+// functions available to templates in addition to the builtin ones
+var templateFuncs = map[string]any{
+	"upper": func(v any) string {
+		return strings.ToUpper(fmt.Sprint(v))
+	},
+	"lower": func(v any) string {
+		return strings.ToLower(fmt.Sprint(v))
+	},
+	"formatTime": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+	"truncate": func(n int, s string) string {
+		if len(s) <= n {
+			return s
+		}
+		return s[:n] + "..."
+	},
+}
+
+// DefaultTemplate returns the template used by channels of the type without an override
+func DefaultTemplate(model.ChannelType) model.Template {
+	return model.Template{Format: model.TemplateFormatText, Body: defaultTextTemplate}
+}
+
+// TemplateOf returns the template of the channel, or the default template of its type
+func TemplateOf(channel *model.Channel) model.Template {
+	if channel.Template != nil {
+		return *channel.Template
+	}
+	return DefaultTemplate(channel.Type)
+}
+
+// Parse parses the template, so its syntax errors can be reported before it is used
+func Parse(t model.Template) (func(msg *Message) (string, error), error) {
+	var execute func(buf *limitedBuffer, msg *Message) error
+
+	switch t.Format {
+	case model.TemplateFormatText, "":
+		parsed, err := texttemplate.New("message").Funcs(templateFuncs).Option("missingkey=error").Parse(t.Body)
+		if err != nil {
+			return nil, err
+		}
+		execute = func(buf *limitedBuffer, msg *Message) error { return parsed.Execute(buf, msg) }
+	case model.TemplateFormatHtml:
+		parsed, err := htmltemplate.New("message").Funcs(templateFuncs).Option("missingkey=error").Parse(t.Body)
+		if err != nil {
+			return nil, err
+		}
+		execute = func(buf *limitedBuffer, msg *Message) error { return parsed.Execute(buf, msg) }
+	default:
+		return nil, fmt.Errorf("unsupported template format %q", t.Format)
+	}
+
+	return func(msg *Message) (string, error) {
+		buf := &limitedBuffer{limit: maxRenderedSize}
+		if err := execute(buf, msg); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}, nil
+}
+
+// Render executes the template with the message
+func Render(t model.Template, msg *Message) (string, error) {
+	render, err := Parse(t)
+	if err != nil {
+		return "", err
+	}
+	return render(msg)
+}
+
+// SampleMessage returns a message of a down alert, used to preview templates
+func SampleMessage(now time.Time) *Message {
+	url := &model.URL{
+		Id:        "507f1f77bcf86cd799439011",
+		Url:       "https://example.com/health",
+		Threshold: 5,
+		Interval:  model.Interval{Duration: time.Minute},
+		Tags:      []string{"production"},
+	}
+
+	alert := &model.Alert{
+		Id:         "507f191e810c19729de860ea",
+		UrlId:      url.Id,
+		Url:        url.Url,
+		IssuedAt:   now,
+		IncidentId: "507f191e810c19729de860eb",
+		Type:       model.AlertTypeDown,
+		Severity:   model.SeverityOf(model.AlertTypeDown),
+		State:      model.AlertStateOpen,
+	}
+
+	return NewMessage(alert, url, &CheckResult{
+		StatusCode: 503,
+		Body:       "service unavailable",
+		CheckedAt:  now,
+	})
+}
+
+var errTooLarge = errors.New("rendered message is too large")
+
+// a buffer that fails when more than limit bytes are written
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, errTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+// Preview is a template rendered with sample data
+type Preview struct {
+	Output string   `json:"output"`
+	Error  string   `json:"error,omitempty" description:"parse or execution error of the template, empty if it was rendered"`
+	Data   *Message `json:"data" description:"the sample data the template was executed with"`
+}
+
+// PreviewOf renders the template with a sample message. template errors are reported in the preview
+func PreviewOf(t model.Template, now time.Time) *Preview {
+	preview := &Preview{Data: SampleMessage(now)}
+
+	output, err := Render(t, preview.Data)
+	if err != nil {
+		preview.Error = err.Error()
+		return preview
+	}
+
+	preview.Output = output
+	return preview
+}
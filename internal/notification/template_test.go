@@ -0,0 +1,58 @@
+package notification_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
+)
+
+func TestRenderDefaultTemplate(t *testing.T) {
+	msg := notification.SampleMessage(time.Date(2022, 10, 8, 12, 0, 0, 0, time.UTC))
+
+	text, err := notification.Render(notification.DefaultTemplate(model.ChannelTypeWebhook), msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "[CRITICAL] https://example.com/health is down (status code 503 at 2022-10-08T12:00:00Z)"
+	if text != expected {
+		t.Fatalf("unexpected text: %q", text)
+	}
+
+	// the result is unknown after a restart
+	msg.Result = nil
+	if _, err := notification.Render(notification.DefaultTemplate(model.ChannelTypeWebhook), msg); err != nil {
+		t.Fatalf("default template failed without a result: %v", err)
+	}
+}
+
+func TestHtmlTemplateEscapes(t *testing.T) {
+	msg := notification.SampleMessage(time.Now())
+	msg.Result.Body = "<script>"
+
+	text, err := notification.Render(model.Template{Format: model.TemplateFormatHtml, Body: "<p>{{.Result.Body}}</p>"}, msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(text, "<script>") {
+		t.Fatalf("body was not escaped: %q", text)
+	}
+}
+
+func TestPreviewReportsErrors(t *testing.T) {
+	for _, body := range []string{"{{.Url.Url", "{{.Missing}}", "{{.Url.Nope}}"} {
+		preview := notification.PreviewOf(model.Template{Format: model.TemplateFormatText, Body: body}, time.Now())
+		if preview.Error == "" {
+			t.Fatalf("no error was reported for %q: %q", body, preview.Output)
+		}
+	}
+
+	preview := notification.PreviewOf(model.Template{Format: model.TemplateFormatText, Body: "{{.Url.Url | upper}}"}, time.Now())
+	if preview.Error != "" || preview.Output != "HTTPS://EXAMPLE.COM/HEALTH" {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+}
@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// WebhookSender posts the message as json to the url of the channel
+type WebhookSender struct {
+	client *http.Client
+}
+
+// the payload of webhooks is the message and its text rendered by the template of the channel
+type webhookPayload struct {
+	*Message
+	Text string `json:"text"`
+}
+
+func (s *WebhookSender) Send(ctx context.Context, channel *model.Channel, msg *Message, text string) error {
+	body, err := json.Marshal(webhookPayload{Message: msg, Text: text})
+	if err != nil {
+		return fmt.Errorf("could not marshal message: %w", err)
+	}
+
+	return postJSON(ctx, s.client, channel.Url, body)
+}
+
+// rate limited requests are retried this many times, if the endpoint asks to wait at most maxRetryAfter
+const (
+	maxRateLimitRetries = 2
+	maxRetryAfter       = 30 * time.Second
+	// the wait when a rate limited response does not have a valid Retry-After header
+	defaultRetryAfter = time.Second
+)
+
+// RateLimitedError is returned when the endpoint still rejects the request with 429 Too Many Requests after the retries
+type RateLimitedError struct {
+	// RetryAfter is how long the endpoint asked to wait before the next request
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %v", e.RetryAfter)
+}
+
+// postJSON posts body to url. requests rejected with 429 are retried after the wait of their Retry-After header
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte) error {
+	for retries := 0; ; retries++ {
+		retryAfter, err := post(ctx, client, url, body)
+		if err != nil || retryAfter < 0 {
+			return err
+		}
+
+		if retries == maxRateLimitRetries || retryAfter > maxRetryAfter {
+			return &RateLimitedError{RetryAfter: retryAfter}
+		}
+
+		timer := time.NewTimer(retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &RateLimitedError{RetryAfter: retryAfter}
+		case <-timer.C:
+		}
+	}
+}
+
+// post sends a single request. if it is rate limited, the wait requested by the endpoint is returned, otherwise a negative duration
+func post(ctx context.Context, client *http.Client, url string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return -1, fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return -1, fmt.Errorf("could not send request: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		return parseRetryAfter(res.Header.Get("Retry-After"), time.Now()), nil
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return -1, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return -1, nil
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either seconds or an http date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+
+	return defaultRetryAfter
+}
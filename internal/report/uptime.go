@@ -0,0 +1,123 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+// Uptime computes the availability of a url in window from its stat buckets.
+// a bucket is down when most of its checks have failed, and an incident lasts from the first down bucket
+// until the start of the next up bucket. buckets without checks do not change the state.
+// the time in excluded windows (e.g. planned maintenance) is neither counted as monitored time nor as downtime.
+func Uptime(stats []model.Stat, alerts []*model.Alert, window model.TimeWindow, excluded []model.TimeWindow) model.UptimeReport {
+	r := model.UptimeReport{
+		From: window.Start,
+		To:   window.End,
+	}
+
+	for _, a := range alerts {
+		if !a.IssuedAt.Before(window.Start) && a.IssuedAt.Before(window.End) {
+			r.AlertCount++
+		}
+	}
+
+	if len(stats) == 0 {
+		return r
+	}
+
+	r.Resolution = stats[0].Resolution
+
+	var (
+		downtime      time.Duration
+		incidentStart time.Time
+		inIncident    bool
+	)
+
+	for _, s := range stats {
+		r.SuccessCount += s.SuccessCount
+		r.FailureCount += s.FailureCount
+
+		if s.SuccessCount == 0 && s.FailureCount == 0 {
+			continue
+		}
+
+		down := s.FailureCount > s.SuccessCount
+		if down && !inIncident {
+			inIncident = true
+			incidentStart = s.Start
+			r.Incidents++
+		} else if !down && inIncident {
+			inIncident = false
+			downtime += effective(model.TimeWindow{Start: incidentStart, End: s.Start}, window, excluded)
+		}
+	}
+
+	if inIncident {
+		downtime += effective(model.TimeWindow{Start: incidentStart, End: window.End}, window, excluded)
+	}
+
+	monitoredWindow := window
+	if stats[0].Start.After(monitoredWindow.Start) {
+		monitoredWindow.Start = stats[0].Start
+	}
+	monitored := effective(monitoredWindow, window, excluded)
+
+	r.Monitored = model.Interval{Duration: monitored}
+	r.Downtime = model.Interval{Duration: downtime}
+
+	if monitored > 0 {
+		r.UptimePercentage = 100 * float64(monitored-downtime) / float64(monitored)
+	}
+
+	if r.Incidents > 0 {
+		r.MTTR = model.Interval{Duration: downtime / time.Duration(r.Incidents)}
+		r.MTBF = model.Interval{Duration: (monitored - downtime) / time.Duration(r.Incidents)}
+	}
+
+	return r
+}
+
+// returns the duration of w inside window, minus the excluded parts
+func effective(w model.TimeWindow, window model.TimeWindow, excluded []model.TimeWindow) time.Duration {
+	total := w.Overlap(window)
+	if total == 0 {
+		return 0
+	}
+
+	if w.Start.Before(window.Start) {
+		w.Start = window.Start
+	}
+	if w.End.After(window.End) {
+		w.End = window.End
+	}
+
+	for _, e := range mergeWindows(excluded) {
+		total -= w.Overlap(e)
+	}
+
+	return total
+}
+
+// merges overlapping windows so the excluded time is not subtracted twice
+func mergeWindows(windows []model.TimeWindow) []model.TimeWindow {
+	sorted := make([]model.TimeWindow, len(windows))
+	copy(sorted, windows)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	merged := make([]model.TimeWindow, 0, len(sorted))
+	for _, w := range sorted {
+		if n := len(merged); n > 0 && !w.Start.After(merged[n-1].End) {
+			if w.End.After(merged[n-1].End) {
+				merged[n-1].End = w.End
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+
+	return merged
+}
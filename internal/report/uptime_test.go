@@ -0,0 +1,94 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/report"
+)
+
+var base = time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func minute(m int, success, failure int) model.Stat {
+	return model.Stat{
+		Resolution:   model.ResolutionMinute,
+		Start:        base.Add(time.Duration(m) * time.Minute),
+		SuccessCount: success,
+		FailureCount: failure,
+	}
+}
+
+func TestUptime(t *testing.T) {
+	window := model.TimeWindow{Start: base, End: base.Add(100 * time.Minute)}
+	stats := []model.Stat{
+		minute(0, 1, 0),
+		minute(10, 0, 1), // first incident starts
+		minute(15, 0, 1),
+		minute(20, 1, 0), // recovered after 10 minutes
+		minute(50, 1, 2), // second incident starts
+		minute(60, 2, 0), // recovered after 10 minutes
+	}
+	alerts := []*model.Alert{
+		{IssuedAt: base.Add(12 * time.Minute)},
+		{IssuedAt: base.Add(200 * time.Minute)},
+	}
+
+	r := report.Uptime(stats, alerts, window, nil)
+
+	if r.Incidents != 2 {
+		t.Fatalf("unexpected number of incidents: %v", r.Incidents)
+	}
+
+	if r.Downtime.Duration != 20*time.Minute {
+		t.Fatalf("unexpected downtime: %v", r.Downtime)
+	}
+
+	if r.UptimePercentage != 80 {
+		t.Fatalf("unexpected uptime percentage: %v", r.UptimePercentage)
+	}
+
+	if r.MTTR.Duration != 10*time.Minute || r.MTBF.Duration != 40*time.Minute {
+		t.Fatalf("unexpected mttr or mtbf: %v %v", r.MTTR, r.MTBF)
+	}
+
+	if r.SuccessCount != 5 || r.FailureCount != 4 || r.AlertCount != 1 {
+		t.Fatalf("unexpected counts: %v", r)
+	}
+}
+
+func TestUptimeOpenIncidentAndExcludedWindows(t *testing.T) {
+	window := model.TimeWindow{Start: base, End: base.Add(100 * time.Minute)}
+	stats := []model.Stat{
+		minute(0, 1, 0),
+		minute(80, 0, 1), // still down at the end of the window
+	}
+	excluded := []model.TimeWindow{
+		{Start: base.Add(70 * time.Minute), End: base.Add(90 * time.Minute)},
+		{Start: base.Add(85 * time.Minute), End: base.Add(95 * time.Minute)},
+	}
+
+	r := report.Uptime(stats, nil, window, excluded)
+
+	if r.Incidents != 1 {
+		t.Fatalf("unexpected number of incidents: %v", r.Incidents)
+	}
+
+	if r.Monitored.Duration != 75*time.Minute {
+		t.Fatalf("unexpected monitored time: %v", r.Monitored)
+	}
+
+	if r.Downtime.Duration != 5*time.Minute {
+		t.Fatalf("unexpected downtime: %v", r.Downtime)
+	}
+}
+
+func TestUptimeWithoutStats(t *testing.T) {
+	window := model.TimeWindow{Start: base, End: base.Add(time.Hour)}
+
+	r := report.Uptime(nil, nil, window, nil)
+
+	if r.Incidents != 0 || r.Downtime.Duration != 0 || r.UptimePercentage != 0 {
+		t.Fatalf("unexpected report: %v", r)
+	}
+}
@@ -1,15 +1,21 @@
 package request
 
 import (
+	"time"
+
 	"github.com/MeysamBavi/http-monitoring/internal/model"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 type DayStats struct {
-	UrlId string `param:"id" path:"id" description:"url id" required:"true"`
-	Day   *int   `query:"day" description:"day of the month (1-31)"`
-	Month *int   `query:"month" description:"month number (1-12)"`
-	Year  *int   `query:"year"`
+	UrlId string     `param:"id" path:"id" description:"url id" required:"true"`
+	Day   *int       `query:"day" description:"day of the month (1-31)"`
+	Month *int       `query:"month" description:"month number (1-12)"`
+	Year  *int       `query:"year"`
+	From  *time.Time `query:"from" description:"start of the range (RFC 3339). days are in UTC and included if they overlap the range"`
+	To    *time.Time `query:"to" description:"end of the range (RFC 3339)"`
+	// days are counted in utc, so the time zone only changes how their starts are rendered
+	TimeZone string `query:"time_zone" description:"IANA time zone to render day starts in, defaults to the time zone of the user. days are always aligned in UTC" example:"Asia/Tehran"`
 }
 
 func (d *DayStats) Validate() error {
@@ -17,6 +23,8 @@ func (d *DayStats) Validate() error {
 		validation.Field(&d.UrlId, validation.Required, validation.By(parsableId)),
 		validation.Field(&d.Day, validation.Min(1), validation.Max(31)),
 		validation.Field(&d.Month, validation.Min(1), validation.Max(12)),
+		validation.Field(&d.From, validation.By(func(any) error { return timeRangeRule(d.From, d.To) })),
+		validation.Field(&d.TimeZone, validation.By(loadableTimeZone)),
 	)
 }
 
@@ -34,10 +42,23 @@ func (d *DayStats) DayFilter() func(date model.Date) bool {
 			return false
 		}
 
+		if d.From != nil && !date.Start().Add(24*time.Hour).After(*d.From) {
+			return false
+		}
+
+		if d.To != nil && !date.Start().Before(*d.To) {
+			return false
+		}
+
 		return true
 	}
 }
 
+// Location returns the requested time zone, or userTimeZone if none was requested
+func (d *DayStats) Location(userTimeZone string) *time.Location {
+	return loadLocation(d.TimeZone, userTimeZone)
+}
+
 func (d *DayStats) ParseUrlId() model.ID {
 	id, err := model.ParseId(d.UrlId)
 	if err != nil {
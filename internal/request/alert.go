@@ -2,20 +2,32 @@ package request
 
 import (
 	"errors"
+	"time"
+
 	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
 type Alert struct {
 	UrlId string `param:"id" path:"id" description:"url id" required:"true"`
+	State string `query:"state" description:"only alerts in this state" enum:"open,acknowledged,resolved"`
 }
 
 func (a *Alert) Validate() error {
 	return validation.ValidateStruct(a,
 		validation.Field(&a.UrlId, validation.Required, validation.By(parsableId)),
+		validation.Field(&a.State, validation.In(string(model.AlertStateOpen), string(model.AlertStateAcknowledged), string(model.AlertStateResolved))),
 	)
 }
 
+// StateFilter returns whether an alert matches the requested state
+func (a *Alert) StateFilter() func(alert *model.Alert) bool {
+	return func(alert *model.Alert) bool {
+		return a.State == "" || string(alert.CurrentState()) == a.State
+	}
+}
+
 func parsableId(id any) error {
 	idStr, ok := id.(string)
 	if !ok {
@@ -36,3 +48,113 @@ func (a *Alert) ParseUrlId() model.ID {
 	}
 	return id
 }
+
+type AlertUpdate struct {
+	Id    string `param:"id" path:"id" description:"alert id" required:"true"`
+	State string `json:"state" description:"new state of the alert" enum:"acknowledged,resolved"`
+	Note  string `json:"note" description:"note to add to the alert"`
+}
+
+func (a *AlertUpdate) Validate() error {
+	return validation.ValidateStruct(a,
+		validation.Field(&a.Id, validation.Required, validation.By(parsableId)),
+		validation.Field(&a.State, validation.Required.When(a.Note == "").Error("either state or note is required"),
+			validation.In(string(model.AlertStateAcknowledged), string(model.AlertStateResolved))),
+		validation.Field(&a.Note, validation.Length(0, 2000)),
+	)
+}
+
+func (a *AlertUpdate) ParseId() model.ID {
+	id, err := model.ParseId(a.Id)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+const (
+	defaultAlertsLimit = 50
+	maxAlertsLimit     = 200
+)
+
+type Alerts struct {
+	UrlId  string     `query:"url_id" description:"only alerts of this url"`
+	Type   string     `query:"type" description:"only alerts of this type" enum:"down,flapping"`
+	State  string     `query:"state" description:"only alerts in this state" enum:"open,acknowledged,resolved"`
+	From   *time.Time `query:"from" description:"only alerts issued at or after this time (RFC 3339)"`
+	To     *time.Time `query:"to" description:"only alerts issued before this time (RFC 3339)"`
+	Cursor string     `query:"cursor" description:"cursor of the page, returned as 'next_cursor' by the previous page"`
+	Limit  *int       `query:"limit" description:"maximum number of alerts in the page (1-200), defaults to 50"`
+}
+
+func (a *Alerts) Validate() error {
+	return validation.ValidateStruct(a,
+		validation.Field(&a.UrlId, validation.By(parsableId)),
+		validation.Field(&a.Type, validation.In(string(model.AlertTypeDown), string(model.AlertTypeFlapping))),
+		validation.Field(&a.State, validation.In(string(model.AlertStateOpen), string(model.AlertStateAcknowledged), string(model.AlertStateResolved))),
+		validation.Field(&a.From, validation.By(func(any) error { return timeRangeRule(a.From, a.To) })),
+		validation.Field(&a.Cursor, validation.By(parsableCursor)),
+		validation.Field(&a.Limit, validation.Min(1), validation.Max(maxAlertsLimit)),
+	)
+}
+
+func parsableCursor(value any) error {
+	cursor, ok := value.(string)
+	if !ok {
+		return errors.New("cursor is not a string")
+	}
+
+	if cursor == "" {
+		return nil
+	}
+
+	_, err := model.ParseAlertCursor(cursor)
+	return err
+}
+
+func (a *Alerts) Filter() store.AlertFilter {
+	filter := store.AlertFilter{
+		From: a.From,
+		To:   a.To,
+	}
+
+	if a.UrlId != "" {
+		id, err := model.ParseId(a.UrlId)
+		if err != nil {
+			panic(err)
+		}
+		filter.UrlId = &id
+	}
+
+	if a.Type != "" {
+		typ := model.AlertType(a.Type)
+		filter.Type = &typ
+	}
+
+	if a.State != "" {
+		state := model.AlertState(a.State)
+		filter.State = &state
+	}
+
+	return filter
+}
+
+// After returns the parsed cursor, or nil for the first page
+func (a *Alerts) After() *model.AlertCursor {
+	if a.Cursor == "" {
+		return nil
+	}
+
+	cursor, err := model.ParseAlertCursor(a.Cursor)
+	if err != nil {
+		panic(err)
+	}
+	return &cursor
+}
+
+func (a *Alerts) PageSize() int {
+	if a.Limit == nil {
+		return defaultAlertsLimit
+	}
+	return *a.Limit
+}
@@ -0,0 +1,134 @@
+package request
+
+import (
+	"errors"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/notification"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type Channel struct {
+	Name string `json:"name" required:"true" example:"on-call webhook"`
+	Type string `json:"type" required:"true" enum:"webhook,slack,discord,mattermost,teams,pagerduty,alertmanager"`
+	Url  string `json:"url" description:"url the notifications are posted to. the events api of pagerduty channels, the base url of alertmanager channels" required:"true"`
+	// RoutingKey is required by pagerduty channels
+	RoutingKey string `json:"routing_key" description:"integration key of the pagerduty service, only used by pagerduty channels"`
+	// Template overrides the default template of the channel type
+	Template *model.Template `json:"template" description:"template of the messages, defaults to the template of the channel type"`
+}
+
+func (c *Channel) Validate() error {
+	return validation.ValidateStruct(c,
+		validation.Field(&c.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&c.Type, validation.Required, validation.In(channelTypes()...)),
+		validation.Field(&c.Url, validation.Required, is.URL),
+		validation.Field(&c.RoutingKey,
+			validation.Required.When(c.Type == string(model.ChannelTypePagerDuty)),
+			validation.Empty.When(c.Type != string(model.ChannelTypePagerDuty)).Error("is only used by pagerduty channels"),
+			validation.Length(1, 100),
+		),
+		validation.Field(&c.Template, validation.By(templateRule)),
+	)
+}
+
+func (c *Channel) Model(userId model.ID) *model.Channel {
+	return &model.Channel{
+		UserId:     userId,
+		Name:       c.Name,
+		Type:       model.ChannelType(c.Type),
+		Url:        c.Url,
+		RoutingKey: c.RoutingKey,
+		Template:   c.Template,
+	}
+}
+
+func channelTypes() []any {
+	types := make([]any, 0, len(model.ChannelTypes))
+	for _, t := range model.ChannelTypes {
+		types = append(types, string(t))
+	}
+	return types
+}
+
+type ChannelId struct {
+	Id string `param:"id" path:"id" description:"channel id" required:"true"`
+}
+
+func (c *ChannelId) Validate() error {
+	return validation.ValidateStruct(c,
+		validation.Field(&c.Id, validation.Required, validation.By(parsableId)),
+	)
+}
+
+func (c *ChannelId) ParseId() model.ID {
+	id, err := model.ParseId(c.Id)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+type ChannelTemplate struct {
+	Id     string `param:"id" path:"id" description:"channel id" required:"true"`
+	Format string `json:"format" description:"text templates use text/template, html templates use html/template" required:"true" enum:"text,html"`
+	Body   string `json:"body" description:"template executed with the message, see the preview endpoint for its data" required:"true" example:"{{.Url.Url}} is {{.Type}}"`
+}
+
+func (t *ChannelTemplate) Validate() error {
+	return validation.ValidateStruct(t,
+		validation.Field(&t.Id, validation.Required, validation.By(parsableId)),
+		validation.Field(&t.Format, validation.Required, validation.In(string(model.TemplateFormatText), string(model.TemplateFormatHtml))),
+		validation.Field(&t.Body, validation.Required, validation.Length(1, 10_000), validation.By(func(any) error { return templateRule(t.Template()) })),
+	)
+}
+
+func (t *ChannelTemplate) Template() *model.Template {
+	return &model.Template{Format: model.TemplateFormat(t.Format), Body: t.Body}
+}
+
+func (t *ChannelTemplate) ParseId() model.ID {
+	id, err := model.ParseId(t.Id)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+type TemplatePreview struct {
+	Format string `json:"format" required:"true" enum:"text,html"`
+	Body   string `json:"body" required:"true" example:"{{.Url.Url}} is {{.Type}}"`
+}
+
+func (t *TemplatePreview) Validate() error {
+	return validation.ValidateStruct(t,
+		validation.Field(&t.Format, validation.Required, validation.In(string(model.TemplateFormatText), string(model.TemplateFormatHtml))),
+		validation.Field(&t.Body, validation.Required, validation.Length(1, 10_000)),
+	)
+}
+
+func (t *TemplatePreview) Template() model.Template {
+	return model.Template{Format: model.TemplateFormat(t.Format), Body: t.Body}
+}
+
+func templateRule(value any) error {
+	template, ok := value.(*model.Template)
+	if !ok {
+		return errors.New("could not convert value to template type")
+	}
+
+	if template == nil {
+		return nil
+	}
+
+	if len(template.Body) > 10_000 {
+		return errors.New("template must be at most 10000 characters")
+	}
+
+	if _, err := notification.Parse(*template); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package request
+
+import (
+	"errors"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type EscalationPolicy struct {
+	Name  string                 `json:"name" required:"true" example:"business hours"`
+	Steps []model.EscalationStep `json:"steps" description:"steps in order, each notifying its channels 'after' the alert was issued" required:"true"`
+}
+
+func (p *EscalationPolicy) Validate() error {
+	return validation.ValidateStruct(p,
+		validation.Field(&p.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&p.Steps, validation.Required, validation.Length(1, 10), validation.By(func(any) error { return stepsRule(p.Steps) })),
+	)
+}
+
+func stepsRule(steps []model.EscalationStep) error {
+	for i, step := range steps {
+		if step.After.Duration < 0 {
+			return errors.New("'after' can not be negative")
+		}
+
+		if i > 0 && step.After.Duration <= steps[i-1].After.Duration {
+			return errors.New("'after' of the steps must be increasing")
+		}
+
+		if len(step.ChannelIds) == 0 {
+			return errors.New("each step must notify at least one channel")
+		}
+
+		for _, id := range step.ChannelIds {
+			if err := parsableId(string(id)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ChannelIds returns the channels notified by the policy
+func (p *EscalationPolicy) ChannelIds() []model.ID {
+	ids := make([]model.ID, 0)
+	for _, step := range p.Steps {
+		ids = append(ids, step.ChannelIds...)
+	}
+	return ids
+}
+
+func (p *EscalationPolicy) Model(userId model.ID) *model.EscalationPolicy {
+	return &model.EscalationPolicy{
+		UserId: userId,
+		Name:   p.Name,
+		Steps:  p.Steps,
+	}
+}
+
+type EscalationPolicyId struct {
+	Id string `param:"id" path:"id" description:"escalation policy id" required:"true"`
+}
+
+func (p *EscalationPolicyId) Validate() error {
+	return validation.ValidateStruct(p,
+		validation.Field(&p.Id, validation.Required, validation.By(parsableId)),
+	)
+}
+
+func (p *EscalationPolicyId) ParseId() model.ID {
+	id, err := model.ParseId(p.Id)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
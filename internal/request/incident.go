@@ -0,0 +1,64 @@
+package request
+
+import (
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"github.com/MeysamBavi/http-monitoring/internal/store"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Incidents struct {
+	UrlId string     `query:"url_id" description:"only incidents of this url"`
+	State string     `query:"state" description:"only incidents in this state" enum:"open,resolved"`
+	From  *time.Time `query:"from" description:"only incidents that were open after this time (RFC 3339)"`
+	To    *time.Time `query:"to" description:"only incidents started before this time (RFC 3339)"`
+}
+
+func (i *Incidents) Validate() error {
+	return validation.ValidateStruct(i,
+		validation.Field(&i.UrlId, validation.By(parsableId)),
+		validation.Field(&i.State, validation.In(string(model.IncidentStateOpen), string(model.IncidentStateResolved))),
+		validation.Field(&i.From, validation.By(func(any) error { return timeRangeRule(i.From, i.To) })),
+	)
+}
+
+func (i *Incidents) Filter() store.IncidentFilter {
+	filter := store.IncidentFilter{
+		From: i.From,
+		To:   i.To,
+	}
+
+	if i.UrlId != "" {
+		id, err := model.ParseId(i.UrlId)
+		if err != nil {
+			panic(err)
+		}
+		filter.UrlId = &id
+	}
+
+	if i.State != "" {
+		state := model.IncidentState(i.State)
+		filter.State = &state
+	}
+
+	return filter
+}
+
+type Incident struct {
+	Id string `param:"id" path:"id" description:"incident id" required:"true"`
+}
+
+func (i *Incident) Validate() error {
+	return validation.ValidateStruct(i,
+		validation.Field(&i.Id, validation.Required, validation.By(parsableId)),
+	)
+}
+
+func (i *Incident) ParseId() model.ID {
+	id, err := model.ParseId(i.Id)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
@@ -0,0 +1,144 @@
+package request
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Maintenance struct {
+	UrlId      string         `json:"url_id" description:"url in maintenance, exclusive with 'tag'"`
+	Tag        string         `json:"tag" description:"urls with this tag are in maintenance, exclusive with 'url_id'"`
+	Start      *time.Time     `json:"start" description:"start of a one-off window (RFC 3339)"`
+	End        *time.Time     `json:"end" description:"end of a one-off window (RFC 3339)"`
+	Cron       string         `json:"cron" description:"start times of a recurring window, exclusive with 'start' and 'end'" example:"0 2 * * 6"`
+	Duration   model.Interval `json:"duration" description:"length of each occurrence of a recurring window" type:"string" example:"2h"`
+	TimeZone   string         `json:"time_zone" description:"IANA time zone of the cron expression, defaults to UTC" example:"Asia/Tehran"`
+	SkipChecks bool           `json:"skip_checks" description:"do not check the urls during the window"`
+	Comment    string         `json:"comment"`
+}
+
+func (m *Maintenance) Validate() error {
+	recurring := m.Cron != ""
+	return validation.ValidateStruct(m,
+		validation.Field(&m.UrlId, validation.Required.When(m.Tag == ""), validation.Empty.When(m.Tag != "").Error("can not be set with 'tag'"), validation.By(optionalParsableId)),
+		validation.Field(&m.Tag, validation.By(tagRule)),
+		validation.Field(&m.Start, validation.Required.When(!recurring), validation.Nil.When(recurring).Error("can not be set with 'cron'")),
+		validation.Field(&m.End, validation.Required.When(!recurring), validation.Nil.When(recurring).Error("can not be set with 'cron'"),
+			validation.By(func(any) error { return timeRangeRule(m.Start, m.End) })),
+		validation.Field(&m.Cron, validation.By(cronRule)),
+		validation.Field(&m.Duration, validation.By(func(value any) error {
+			if recurring && m.Duration.Duration <= 0 {
+				return errors.New("must be positive for a recurring window")
+			}
+			return nil
+		})),
+		validation.Field(&m.TimeZone, validation.When(m.TimeZone != "", validation.By(loadableTimeZone))),
+		validation.Field(&m.Comment, validation.Length(0, 500)),
+	)
+}
+
+// Model returns the requested maintenance window of the user
+func (m *Maintenance) Model(userId model.ID) *model.Maintenance {
+	result := &model.Maintenance{
+		UserId:     userId,
+		Tag:        m.Tag,
+		Cron:       m.Cron,
+		Duration:   m.Duration,
+		TimeZone:   m.TimeZone,
+		SkipChecks: m.SkipChecks,
+		Comment:    m.Comment,
+	}
+
+	if m.UrlId != "" {
+		id, err := model.ParseId(m.UrlId)
+		if err != nil {
+			panic(err)
+		}
+		result.UrlId = id
+	}
+
+	if m.Cron == "" {
+		result.Start = *m.Start
+		result.End = *m.End
+		result.Duration = model.Interval{Duration: m.End.Sub(*m.Start)}
+	}
+
+	return result
+}
+
+type MaintenanceId struct {
+	Id string `param:"id" path:"id" description:"maintenance id" required:"true"`
+}
+
+func (m *MaintenanceId) Validate() error {
+	return validation.ValidateStruct(m,
+		validation.Field(&m.Id, validation.Required, validation.By(parsableId)),
+	)
+}
+
+func (m *MaintenanceId) ParseId() model.ID {
+	id, err := model.ParseId(m.Id)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func optionalParsableId(value any) error {
+	if id, ok := value.(string); ok && id == "" {
+		return nil
+	}
+	return parsableId(value)
+}
+
+func cronRule(value any) error {
+	expr, ok := value.(string)
+	if !ok {
+		return errors.New("cron expression is not a string")
+	}
+
+	if expr == "" {
+		return nil
+	}
+
+	_, err := model.ParseCron(expr)
+	return err
+}
+
+func tagRule(value any) error {
+	tag, ok := value.(string)
+	if !ok {
+		return errors.New("tag is not a string")
+	}
+
+	if len(tag) > 50 {
+		return errors.New("tag must be at most 50 characters")
+	}
+
+	return nil
+}
+
+func tagsRule(value any) error {
+	tags, ok := value.([]string)
+	if !ok {
+		return errors.New("tags are not a list of strings")
+	}
+
+	if len(tags) > 20 {
+		return errors.New("a url can have at most 20 tags")
+	}
+
+	for _, tag := range tags {
+		if tag == "" {
+			return errors.New("tags can not be empty")
+		}
+		if err := tagRule(tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package request
+
+import (
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	defaultNotificationJobsLimit = 50
+	maxNotificationJobsLimit     = 200
+)
+
+type NotificationJobs struct {
+	Status string `query:"status" description:"only jobs with this status" enum:"pending,succeeded,dead"`
+	Limit  *int   `query:"limit" description:"maximum number of jobs (1-200), defaults to 50"`
+}
+
+func (n *NotificationJobs) Validate() error {
+	statuses := make([]any, 0, len(model.NotificationJobStatuses))
+	for _, s := range model.NotificationJobStatuses {
+		statuses = append(statuses, string(s))
+	}
+
+	return validation.ValidateStruct(n,
+		validation.Field(&n.Status, validation.In(statuses...)),
+		validation.Field(&n.Limit, validation.Min(1), validation.Max(maxNotificationJobsLimit)),
+	)
+}
+
+func (n *NotificationJobs) JobStatus() model.NotificationJobStatus {
+	return model.NotificationJobStatus(n.Status)
+}
+
+func (n *NotificationJobs) PageSize() int {
+	if n.Limit == nil {
+		return defaultNotificationJobsLimit
+	}
+	return *n.Limit
+}
+
+type NotificationJobId struct {
+	Id string `param:"id" path:"id" description:"notification job id" required:"true"`
+}
+
+func (n *NotificationJobId) Validate() error {
+	return validation.ValidateStruct(n,
+		validation.Field(&n.Id, validation.Required, validation.By(parsableId)),
+	)
+}
+
+func (n *NotificationJobId) ParseId() model.ID {
+	id, err := model.ParseId(n.Id)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
@@ -0,0 +1,139 @@
+package request
+
+import (
+	"errors"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type RoutingRule struct {
+	Name       string   `json:"name" required:"true" example:"critical to on-call"`
+	Order      int      `json:"order" description:"rules are evaluated in ascending order"`
+	UrlIds     []string `json:"url_ids" description:"match alerts of these urls, empty matches all urls"`
+	Tags       []string `json:"tags" description:"match urls with any of these tags, empty matches all urls"`
+	Types      []string `json:"types" description:"match these alert types (down, flapping, recovered, latency), empty matches all types"`
+	Severities []string `json:"severities" description:"match these severities (critical, warning, info), empty matches all severities"`
+	ChannelIds []string `json:"channel_ids" description:"channels notified of the matched alerts" required:"true"`
+	Stop       bool     `json:"stop" description:"do not evaluate the next rules if this rule matches"`
+}
+
+func (r *RoutingRule) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&r.UrlIds, validation.Each(validation.By(parsableId))),
+		validation.Field(&r.Tags, validation.By(tagsRule)),
+		validation.Field(&r.Types, validation.Each(validation.In(alertTypes()...))),
+		validation.Field(&r.Severities, validation.Each(validation.In(alertSeverities()...))),
+		validation.Field(&r.ChannelIds, validation.Required, validation.Length(1, 10), validation.Each(validation.By(parsableId))),
+	)
+}
+
+func (r *RoutingRule) Model(userId model.ID) *model.RoutingRule {
+	rule := &model.RoutingRule{
+		UserId:     userId,
+		Name:       r.Name,
+		Order:      r.Order,
+		UrlIds:     parseIds(r.UrlIds),
+		Tags:       r.Tags,
+		ChannelIds: parseIds(r.ChannelIds),
+		Stop:       r.Stop,
+	}
+
+	for _, t := range r.Types {
+		rule.Types = append(rule.Types, model.AlertType(t))
+	}
+
+	for _, s := range r.Severities {
+		rule.Severities = append(rule.Severities, model.AlertSeverity(s))
+	}
+
+	return rule
+}
+
+func (r *RoutingRule) ParseChannelIds() []model.ID {
+	return parseIds(r.ChannelIds)
+}
+
+type RoutingRuleId struct {
+	Id string `param:"id" path:"id" description:"routing rule id" required:"true"`
+}
+
+func (r *RoutingRuleId) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Id, validation.Required, validation.By(parsableId)),
+	)
+}
+
+func (r *RoutingRuleId) ParseId() model.ID {
+	id, err := model.ParseId(r.Id)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// RoutingDryRun describes a hypothetical alert. the alert is of the url, or of a url with the tags
+type RoutingDryRun struct {
+	UrlId    string   `json:"url_id" description:"url of the alert, exclusive with 'tags'"`
+	Tags     []string `json:"tags" description:"tags of a hypothetical url, exclusive with 'url_id'"`
+	Type     string   `json:"type" required:"true" enum:"down,flapping,recovered,latency"`
+	Severity string   `json:"severity" description:"defaults to the severity of the type" enum:"critical,warning,info"`
+}
+
+func (r *RoutingDryRun) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.UrlId, validation.By(optionalParsableId)),
+		validation.Field(&r.Tags, validation.By(tagsRule), validation.By(func(any) error {
+			if r.UrlId != "" && len(r.Tags) > 0 {
+				return errors.New("can not be set with 'url_id'")
+			}
+			return nil
+		})),
+		validation.Field(&r.Type, validation.Required, validation.In(alertTypes()...)),
+		validation.Field(&r.Severity, validation.In(alertSeverities()...)),
+	)
+}
+
+func (r *RoutingDryRun) ParseUrlId() model.ID {
+	id, err := model.ParseId(r.UrlId)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func (r *RoutingDryRun) AlertSeverity() model.AlertSeverity {
+	if r.Severity == "" {
+		return model.SeverityOf(model.AlertType(r.Type))
+	}
+	return model.AlertSeverity(r.Severity)
+}
+
+func parseIds(ids []string) []model.ID {
+	parsed := make([]model.ID, 0, len(ids))
+	for _, s := range ids {
+		id, err := model.ParseId(s)
+		if err != nil {
+			panic(err)
+		}
+		parsed = append(parsed, id)
+	}
+	return parsed
+}
+
+func alertTypes() []any {
+	types := make([]any, 0, len(model.AlertTypes))
+	for _, t := range model.AlertTypes {
+		types = append(types, string(t))
+	}
+	return types
+}
+
+func alertSeverities() []any {
+	severities := make([]any, 0, len(model.AlertSeverities))
+	for _, s := range model.AlertSeverities {
+		severities = append(severities, string(s))
+	}
+	return severities
+}
@@ -0,0 +1,109 @@
+package request
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Silence struct {
+	Matchers  []model.SilenceMatcher `json:"matchers" description:"the silence applies to urls matched by all matchers" required:"true"`
+	StartsAt  *time.Time             `json:"starts_at" description:"start of the silence (RFC 3339), defaults to now"`
+	EndsAt    *time.Time             `json:"ends_at" description:"end of the silence (RFC 3339), exclusive with 'duration'"`
+	Duration  model.Interval         `json:"duration" description:"length of the silence, exclusive with 'ends_at'" type:"string" example:"2h"`
+	CreatedBy string                 `json:"created_by" description:"author of the silence, defaults to the username"`
+	Comment   string                 `json:"comment"`
+}
+
+func (s *Silence) Validate() error {
+	return validation.ValidateStruct(s,
+		validation.Field(&s.Matchers, validation.Required, validation.Length(1, 10), validation.Each(validation.By(matcherRule))),
+		validation.Field(&s.EndsAt, validation.Required.When(s.Duration.Duration == 0).Error("'ends_at' or 'duration' is required"),
+			validation.Nil.When(s.Duration.Duration != 0).Error("can not be set with 'duration'"),
+			validation.By(func(any) error {
+				if s.StartsAt == nil && s.EndsAt != nil && !s.EndsAt.After(time.Now()) {
+					return errors.New("must be in the future")
+				}
+				return timeRangeRule(s.StartsAt, s.EndsAt)
+			})),
+		validation.Field(&s.Duration, validation.By(func(any) error {
+			if s.Duration.Duration < 0 {
+				return errors.New("can not be negative")
+			}
+			return nil
+		})),
+		validation.Field(&s.CreatedBy, validation.Length(0, 100)),
+		validation.Field(&s.Comment, validation.Length(0, 500)),
+	)
+}
+
+// Model returns the requested silence of the user, created at now
+func (s *Silence) Model(userId model.ID, createdBy string, now time.Time) *model.Silence {
+	startsAt := now
+	if s.StartsAt != nil {
+		startsAt = *s.StartsAt
+	}
+
+	endsAt := startsAt.Add(s.Duration.Duration)
+	if s.EndsAt != nil {
+		endsAt = *s.EndsAt
+	}
+
+	if s.CreatedBy != "" {
+		createdBy = s.CreatedBy
+	}
+
+	return &model.Silence{
+		UserId:    userId,
+		Matchers:  s.Matchers,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		Comment:   s.Comment,
+	}
+}
+
+func matcherRule(value any) error {
+	matcher, ok := value.(model.SilenceMatcher)
+	if !ok {
+		return errors.New("could not convert value to matcher type")
+	}
+
+	if matcher.Value == "" {
+		return errors.New("matcher value is required")
+	}
+
+	switch matcher.Name {
+	case model.MatcherUrlId:
+		return parsableId(matcher.Value)
+	case model.MatcherUrl, model.MatcherTag:
+		return nil
+	default:
+		return errors.New("matcher name must be one of url_id, url or tag")
+	}
+}
+
+type Silences struct {
+	Expired bool `query:"expired" description:"include expired silences"`
+}
+
+type SilenceId struct {
+	Id string `param:"id" path:"id" description:"silence id" required:"true"`
+}
+
+func (s *SilenceId) Validate() error {
+	return validation.ValidateStruct(s,
+		validation.Field(&s.Id, validation.Required, validation.By(parsableId)),
+	)
+}
+
+func (s *SilenceId) ParseId() model.ID {
+	id, err := model.ParseId(s.Id)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
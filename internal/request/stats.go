@@ -0,0 +1,95 @@
+package request
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	defaultStatsRange = 24 * time.Hour
+	maxStatsBuckets   = 10_000
+)
+
+type Stats struct {
+	UrlId      string     `param:"id" path:"id" description:"url id" required:"true"`
+	Resolution string     `query:"resolution" description:"bucket resolution" enum:"minute,hour,day" required:"true"`
+	From       *time.Time `query:"from" description:"start of the range (RFC 3339), defaults to 24 hours before 'to'"`
+	To         *time.Time `query:"to" description:"end of the range (RFC 3339), defaults to now"`
+	TimeZone   string     `query:"time_zone" description:"IANA time zone to render bucket starts in, defaults to the time zone of the user. buckets are always aligned in UTC" example:"Asia/Tehran"`
+}
+
+func (s *Stats) Validate() error {
+	return validation.ValidateStruct(s,
+		validation.Field(&s.UrlId, validation.Required, validation.By(parsableId)),
+		validation.Field(&s.Resolution, validation.Required, validation.By(parsableResolution)),
+		validation.Field(&s.From, validation.By(s.rangeRule)),
+		validation.Field(&s.TimeZone, validation.By(loadableTimeZone)),
+	)
+}
+
+func parsableResolution(value any) error {
+	str, ok := value.(string)
+	if !ok {
+		return errors.New("resolution is not a string")
+	}
+
+	_, err := model.ParseResolution(str)
+	return err
+}
+
+func (s *Stats) rangeRule(any) error {
+	from, to := s.Range()
+	if err := timeRangeRule(&from, &to); err != nil {
+		return err
+	}
+
+	resolution, err := model.ParseResolution(s.Resolution)
+	if err != nil {
+		return nil
+	}
+
+	if to.Sub(from)/resolution.Duration() > maxStatsBuckets {
+		return errors.New("range is too large for this resolution")
+	}
+
+	return nil
+}
+
+// Range returns the requested time range, filling in the defaults
+func (s *Stats) Range() (from time.Time, to time.Time) {
+	to = time.Now()
+	if s.To != nil {
+		to = *s.To
+	}
+
+	from = to.Add(-defaultStatsRange)
+	if s.From != nil {
+		from = *s.From
+	}
+
+	return from, to
+}
+
+// Location returns the requested time zone, or userTimeZone if none was requested
+func (s *Stats) Location(userTimeZone string) *time.Location {
+	return loadLocation(s.TimeZone, userTimeZone)
+}
+
+func (s *Stats) ParseResolution() model.Resolution {
+	resolution, err := model.ParseResolution(s.Resolution)
+	if err != nil {
+		panic(err)
+	}
+	return resolution
+}
+
+func (s *Stats) ParseUrlId() model.ID {
+	id, err := model.ParseId(s.UrlId)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
@@ -0,0 +1,47 @@
+package request
+
+import (
+	"errors"
+	"time"
+)
+
+func loadableTimeZone(value any) error {
+	name, ok := value.(string)
+	if !ok {
+		return errors.New("time zone is not a string")
+	}
+
+	// "Local" depends on the host, so it is not accepted
+	if name == "Local" {
+		return errors.New("invalid time zone")
+	}
+
+	if _, err := time.LoadLocation(name); err != nil {
+		return errors.New("invalid time zone")
+	}
+
+	return nil
+}
+
+// loadLocation loads the first non-empty time zone name, falling back to UTC
+func loadLocation(names ...string) *time.Location {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+
+	return time.UTC
+}
+
+func timeRangeRule(from, to *time.Time) error {
+	if from != nil && to != nil && !from.Before(*to) {
+		return errors.New("must be before 'to'")
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package request
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var uptimeWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+type Uptime struct {
+	UrlId  string     `param:"id" path:"id" description:"url id" required:"true"`
+	Window string     `query:"window" description:"report window ending now, ignored if 'from' is set. defaults to 24h" enum:"24h,7d,30d"`
+	From   *time.Time `query:"from" description:"start of a custom window (RFC 3339), before now"`
+	To     *time.Time `query:"to" description:"end of a custom window (RFC 3339), defaults to now"`
+	// ExcludeMaintenance removes the maintenance windows of the url from the monitored time. nil means true
+	ExcludeMaintenance *bool `query:"exclude_maintenance" description:"exclude the planned maintenance windows of the url from the report. defaults to true"`
+}
+
+func (u *Uptime) Validate() error {
+	return validation.ValidateStruct(u,
+		validation.Field(&u.UrlId, validation.Required, validation.By(parsableId)),
+		validation.Field(&u.Window, validation.In("24h", "7d", "30d")),
+		validation.Field(&u.From, validation.By(func(any) error { return timeRangeRule(u.From, u.To) }), validation.By(notFuture)),
+	)
+}
+
+// the window ends at now at the latest, so it must start before now
+func notFuture(value any) error {
+	from, _ := value.(*time.Time)
+	if from != nil && !from.Before(time.Now()) {
+		return errors.New("must be in the past")
+	}
+
+	return nil
+}
+
+// TimeWindow returns the requested window, clamped to now
+func (u *Uptime) TimeWindow(now time.Time) model.TimeWindow {
+	end := now
+	if u.To != nil && u.To.Before(now) {
+		end = *u.To
+	}
+
+	if u.From != nil {
+		return model.TimeWindow{Start: *u.From, End: end}
+	}
+
+	length, ok := uptimeWindows[u.Window]
+	if !ok {
+		length = uptimeWindows["24h"]
+	}
+
+	return model.TimeWindow{Start: end.Add(-length), End: end}
+}
+
+// ExcludesMaintenance reports whether the maintenance windows are excluded from the report, which they are by default
+func (u *Uptime) ExcludesMaintenance() bool {
+	return u.ExcludeMaintenance == nil || *u.ExcludeMaintenance
+}
+
+func (u *Uptime) ParseUrlId() model.ID {
+	id, err := model.ParseId(u.UrlId)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
@@ -9,17 +9,129 @@ import (
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 )
 
+// minInterval is the shortest time between two checks of a url
+const minInterval = 5 * time.Second
+
+// maxScheduleRuns is the most runs of a schedule that can be previewed
+const maxScheduleRuns = 100
+
 type URL struct {
 	Url       string         `json:"url" description:"url to monitor" required:"true"`
 	Threshold int            `json:"threshold" description:"failure threshold" required:"true"`
-	Interval  model.Interval `json:"interval" description:"interval between checks" required:"true" type:"string" example:"5m40s"`
+	Interval  model.Interval `json:"interval" description:"interval between checks, required if 'schedule' is not set" type:"string" example:"5m40s"`
+	// Schedule replaces Interval when it is set
+	Schedule *model.Schedule `json:"schedule" description:"cron expressions of the check times, exclusive with 'interval'. the url is checked on the times of all of them"`
+	// AlertPolicy overrides the default alerting policy of the monitor
+	AlertPolicy model.AlertPolicy `json:"alert_policy" description:"alerting policy of the url, unset fields use the defaults"`
+	Tags        []string          `json:"tags" description:"tags of the url, used by maintenance windows" example:"production"`
+	// EscalationPolicyId is the policy used to notify the alerts of the url
+	EscalationPolicyId string `json:"escalation_policy_id" description:"escalation policy used to notify the alerts of the url"`
+	// FailingPolicy changes how often the url is checked while it is failing
+	FailingPolicy *model.FailingPolicy `json:"failing_policy" description:"checks the url more often while it is failing, and optionally backs off when it stays down"`
 }
 
 func (url *URL) Validate() error {
 	return validation.ValidateStruct(url,
 		validation.Field(&url.Url, validation.Required, is.URL),
 		validation.Field(&url.Threshold, validation.Required, validation.Min(5)),
-		validation.Field(&url.Interval, validation.Required, validation.By(intervalMinRule)))
+		validation.Field(&url.Interval, validation.By(func(value any) error {
+			if url.Schedule != nil {
+				if url.Interval.Duration != 0 {
+					return errors.New("can not be set with 'schedule'")
+				}
+				return nil
+			}
+			return intervalMinRule(value)
+		})),
+		validation.Field(&url.Schedule, validation.By(scheduleRule)),
+		validation.Field(&url.AlertPolicy, validation.By(alertPolicyRule)),
+		validation.Field(&url.FailingPolicy, validation.By(failingPolicyRule)),
+		validation.Field(&url.Tags, validation.By(tagsRule)),
+		validation.Field(&url.EscalationPolicyId, validation.By(optionalParsableId)))
+}
+
+func (url *URL) ParseEscalationPolicyId() model.ID {
+	if url.EscalationPolicyId == "" {
+		return ""
+	}
+
+	id, err := model.ParseId(url.EscalationPolicyId)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+type UrlId struct {
+	Id string `param:"id" path:"id" description:"url id" required:"true"`
+}
+
+func (u *UrlId) Validate() error {
+	return validation.ValidateStruct(u,
+		validation.Field(&u.Id, validation.Required, validation.By(parsableId)),
+	)
+}
+
+func (u *UrlId) ParseId() model.ID {
+	id, err := model.ParseId(u.Id)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func alertPolicyRule(value any) error {
+	policy, ok := value.(model.AlertPolicy)
+	if !ok {
+		return errors.New("could not convert value to alert policy type")
+	}
+
+	if policy.Cooldown != nil && policy.Cooldown.Duration < 0 {
+		return errors.New("cooldown can not be negative")
+	}
+
+	if policy.FlapWindow != nil && policy.FlapWindow.Duration < 0 {
+		return errors.New("flap window can not be negative")
+	}
+
+	if policy.FlapThreshold != nil && (*policy.FlapThreshold < 0 || *policy.FlapThreshold == 1) {
+		return errors.New("flap threshold must be zero (disabled) or at least 2")
+	}
+
+	return nil
+}
+
+func failingPolicyRule(value any) error {
+	policy, ok := value.(*model.FailingPolicy)
+	if !ok {
+		return errors.New("could not convert value to failing policy type")
+	}
+
+	if policy == nil {
+		return nil
+	}
+
+	if policy.Interval.Duration < minInterval {
+		return errors.New("failing interval must be at least 5s")
+	}
+
+	if policy.SlowDownAfter.Duration < 0 {
+		return errors.New("slow down after can not be negative")
+	}
+
+	if policy.SlowDownAfter.Duration == 0 {
+		return nil
+	}
+
+	if policy.Backoff <= 1 {
+		return errors.New("backoff must be greater than 1")
+	}
+
+	if policy.MaxInterval.Duration < policy.Interval.Duration {
+		return errors.New("max interval must be at least the failing interval")
+	}
+
+	return nil
 }
 
 func intervalMinRule(value any) error {
@@ -28,9 +140,104 @@ func intervalMinRule(value any) error {
 		return errors.New("could not convert value to interval type")
 	}
 
-	if interval.Duration < time.Second*5 {
+	if interval.Duration < minInterval {
 		return errors.New("interval must be at least 5s")
 	}
 
 	return nil
 }
+
+func scheduleRule(value any) error {
+	schedule, ok := value.(*model.Schedule)
+	if !ok {
+		return errors.New("could not convert value to schedule type")
+	}
+
+	if schedule == nil {
+		return nil
+	}
+
+	if len(schedule.Cron) == 0 {
+		return errors.New("at least one cron expression is required")
+	}
+
+	if len(schedule.Cron) > 10 {
+		return errors.New("at most 10 cron expressions are allowed")
+	}
+
+	if schedule.TimeZone != "" {
+		if err := loadableTimeZone(schedule.TimeZone); err != nil {
+			return err
+		}
+	}
+
+	runs, err := schedule.Runs(time.Now(), 10)
+	if err != nil {
+		return err
+	}
+
+	if len(runs) == 0 {
+		return errors.New("schedule has no runs")
+	}
+
+	for i := 1; i < len(runs); i++ {
+		if runs[i].Sub(runs[i-1]) < minInterval {
+			return errors.New("runs of the schedule must be at least 5s apart")
+		}
+	}
+
+	return nil
+}
+
+type SchedulePreview struct {
+	Schedule *model.Schedule `json:"schedule" description:"schedule to preview" required:"true"`
+	Count    int             `json:"count" description:"number of runs, defaults to 10" example:"5"`
+}
+
+func (s *SchedulePreview) Validate() error {
+	return validation.ValidateStruct(s,
+		validation.Field(&s.Schedule, validation.NotNil, validation.By(scheduleRule)),
+		validation.Field(&s.Count, validation.Min(0), validation.Max(maxScheduleRuns)),
+	)
+}
+
+// Runs returns the requested number of runs of the schedule after now
+func (s *SchedulePreview) Runs(now time.Time) []time.Time {
+	runs, err := s.Schedule.Runs(now, runCount(s.Count))
+	if err != nil {
+		panic(err)
+	}
+	return runs
+}
+
+type UrlRuns struct {
+	UrlId string `param:"id" path:"id" description:"url id" required:"true"`
+	Count int    `query:"count" description:"number of runs, defaults to 10" example:"5"`
+}
+
+func (u *UrlRuns) Validate() error {
+	return validation.ValidateStruct(u,
+		validation.Field(&u.UrlId, validation.Required, validation.By(parsableId)),
+		validation.Field(&u.Count, validation.Min(0), validation.Max(maxScheduleRuns)),
+	)
+}
+
+func (u *UrlRuns) ParseUrlId() model.ID {
+	id, err := model.ParseId(u.UrlId)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// RunCount returns the requested number of runs
+func (u *UrlRuns) RunCount() int {
+	return runCount(u.Count)
+}
+
+func runCount(count int) int {
+	if count == 0 {
+		return 10
+	}
+	return count
+}
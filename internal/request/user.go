@@ -15,3 +15,12 @@ func (u *User) Validate() error {
 		validation.Field(&u.Username, validation.Required, validation.Length(3, 50), is.Alphanumeric),
 		validation.Field(&u.Password, validation.Required, validation.Length(6, 50), is.ASCII))
 }
+
+type Preferences struct {
+	TimeZone string `json:"time_zone" description:"IANA time zone name" required:"true" example:"Asia/Tehran"`
+}
+
+func (p *Preferences) Validate() error {
+	return validation.ValidateStruct(p,
+		validation.Field(&p.TimeZone, validation.Required, validation.By(loadableTimeZone)))
+}
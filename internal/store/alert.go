@@ -2,11 +2,92 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 type Alert interface {
 	GetByUrlId(context.Context, model.ID) ([]*model.Alert, error)
+	// GetByUserId returns at most limit alerts of user matching the filter, the latest first.
+	// if after is not nil, the alerts start after the alert it points to
+	GetByUserId(ctx context.Context, userId model.ID, filter AlertFilter, after *model.AlertCursor, limit int) ([]*model.Alert, error)
 	Add(context.Context, *model.Alert) error
+	Get(ctx context.Context, userId model.ID, id model.ID) (*model.Alert, error)
+	// UpdateState applies the change if the alert is in a state it can change from, or returns a ConflictError
+	UpdateState(ctx context.Context, userId model.ID, id model.ID, change model.AlertStateChange) (*model.Alert, error)
+	AddNote(ctx context.Context, userId model.ID, id model.ID, note model.AlertNote) (*model.Alert, error)
+}
+
+// AlertFilter selects alerts. nil fields match everything.
+// From and To select the alerts issued in [From, To)
+type AlertFilter struct {
+	UrlId *model.ID
+	Type  *model.AlertType
+	State *model.AlertState
+	From  *time.Time
+	To    *time.Time
+}
+
+func (f AlertFilter) Match(a *model.Alert) bool {
+	if f.UrlId != nil && *f.UrlId != a.UrlId {
+		return false
+	}
+
+	if f.Type != nil && *f.Type != a.CurrentType() {
+		return false
+	}
+
+	if f.State != nil && *f.State != a.CurrentState() {
+		return false
+	}
+
+	if f.From != nil && a.IssuedAt.Before(*f.From) {
+		return false
+	}
+
+	if f.To != nil && !a.IssuedAt.Before(*f.To) {
+		return false
+	}
+
+	return true
+}
+
+func (f AlertFilter) query(userId model.ID) bson.M {
+	q := bson.M{"user_id": userId}
+
+	if f.UrlId != nil {
+		q["url_id"] = *f.UrlId
+	}
+
+	// alerts stored before types and states were added don't have these fields
+	if f.Type != nil {
+		if *f.Type == model.AlertTypeDown {
+			q["type"] = bson.M{"$in": bson.A{*f.Type, nil, ""}}
+		} else {
+			q["type"] = *f.Type
+		}
+	}
+
+	if f.State != nil {
+		if *f.State == model.AlertStateOpen {
+			q["state"] = bson.M{"$in": bson.A{*f.State, nil, ""}}
+		} else {
+			q["state"] = *f.State
+		}
+	}
+
+	issuedAt := bson.M{}
+	if f.From != nil {
+		issuedAt["$gte"] = *f.From
+	}
+	if f.To != nil {
+		issuedAt["$lt"] = *f.To
+	}
+	if len(issuedAt) > 0 {
+		q["issued_at"] = issuedAt
+	}
+
+	return q
 }
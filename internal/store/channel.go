@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+type Channel interface {
+	Add(context.Context, *model.Channel) error
+	Get(ctx context.Context, userId model.ID, id model.ID) (*model.Channel, error)
+	GetByUserId(context.Context, model.ID) ([]*model.Channel, error)
+	Delete(ctx context.Context, userId model.ID, id model.ID) error
+	// SetTemplate overrides the template of the channel. a nil template resets it to the default
+	SetTemplate(ctx context.Context, userId model.ID, id model.ID, template *model.Template) (*model.Channel, error)
+}
@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+type EscalationPolicy interface {
+	Add(context.Context, *model.EscalationPolicy) error
+	Get(ctx context.Context, userId model.ID, id model.ID) (*model.EscalationPolicy, error)
+	GetByUserId(context.Context, model.ID) ([]*model.EscalationPolicy, error)
+	Delete(ctx context.Context, userId model.ID, id model.ID) error
+}
+
+type Escalation interface {
+	Add(context.Context, *model.Escalation) error
+	Update(context.Context, *model.Escalation) error
+	// GetDue returns the active escalations of all users whose next step is due at now, the earliest first
+	GetDue(ctx context.Context, now time.Time) ([]*model.Escalation, error)
+	// GetNext returns the active escalation with the earliest next step, or nil if there is none
+	GetNext(ctx context.Context) (*model.Escalation, error)
+}
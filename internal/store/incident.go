@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type Incident interface {
+	Add(context.Context, *model.Incident) error
+	// Update replaces the stored incident with the given one
+	Update(context.Context, *model.Incident) error
+	Get(ctx context.Context, userId model.ID, id model.ID) (*model.Incident, error)
+	// GetByUserId returns the incidents of user matching the filter, the latest first
+	GetByUserId(ctx context.Context, userId model.ID, filter IncidentFilter) ([]*model.Incident, error)
+	// GetOpen returns the open incidents of all users
+	GetOpen(context.Context) ([]*model.Incident, error)
+}
+
+// IncidentFilter selects incidents. nil fields match everything.
+// From and To select the incidents that were open at some point in [From, To)
+type IncidentFilter struct {
+	UrlId *model.ID
+	State *model.IncidentState
+	From  *time.Time
+	To    *time.Time
+}
+
+func (f IncidentFilter) Match(i *model.Incident) bool {
+	if f.UrlId != nil && *f.UrlId != i.UrlId {
+		return false
+	}
+
+	if f.State != nil && *f.State != i.State {
+		return false
+	}
+
+	if f.From != nil && i.ResolvedAt != nil && i.ResolvedAt.Before(*f.From) {
+		return false
+	}
+
+	if f.To != nil && !i.StartedAt.Before(*f.To) {
+		return false
+	}
+
+	return true
+}
+
+func (f IncidentFilter) query(userId model.ID) bson.M {
+	q := bson.M{"user_id": userId}
+
+	if f.UrlId != nil {
+		q["url_id"] = *f.UrlId
+	}
+
+	if f.State != nil {
+		q["state"] = *f.State
+	}
+
+	if f.From != nil {
+		q["$or"] = bson.A{
+			bson.M{"resolved_at": nil},
+			bson.M{"resolved_at": bson.M{"$gte": *f.From}},
+		}
+	}
+
+	if f.To != nil {
+		q["started_at"] = bson.M{"$lt": *f.To}
+	}
+
+	return q
+}
@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+type Maintenance interface {
+	Add(context.Context, *model.Maintenance) error
+	GetByUserId(context.Context, model.ID) ([]*model.Maintenance, error)
+	Delete(ctx context.Context, userId model.ID, id model.ID) error
+	// GetCurrent returns the recurring windows and the one-off windows not ended before now, of all users
+	GetCurrent(ctx context.Context, now time.Time) ([]*model.Maintenance, error)
+}
@@ -3,6 +3,8 @@ package store
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/MeysamBavi/http-monitoring/internal/model"
@@ -10,18 +12,36 @@ import (
 )
 
 type InMemoryStore struct {
-	user   *InMemoryUser
-	url    *InMemoryUrl
-	alert  *InMemoryAlert
-	logger *zap.Logger
+	user             *InMemoryUser
+	url              *InMemoryUrl
+	alert            *InMemoryAlert
+	stat             *InMemoryStat
+	incident         *InMemoryIncident
+	maintenance      *InMemoryMaintenance
+	silence          *InMemorySilence
+	channel          *InMemoryChannel
+	escalationPolicy *InMemoryEscalationPolicy
+	escalation       *InMemoryEscalation
+	routingRule      *InMemoryRoutingRule
+	notificationJob  *InMemoryNotificationJob
+	logger           *zap.Logger
 }
 
 func NewInMemoryStore(logger *zap.Logger) Store {
 	return &InMemoryStore{
-		user:   &InMemoryUser{data: make(map[model.ID]*model.User), usernames: make(map[string]model.ID)},
-		url:    &InMemoryUrl{data: make(map[model.ID][]*model.URL)},
-		alert:  &InMemoryAlert{data: make(map[model.ID][]*model.Alert)},
-		logger: logger,
+		user:             &InMemoryUser{data: make(map[model.ID]*model.User), usernames: make(map[string]model.ID)},
+		url:              &InMemoryUrl{data: make(map[model.ID][]*model.URL)},
+		alert:            &InMemoryAlert{data: make(map[model.ID][]*model.Alert)},
+		stat:             &InMemoryStat{data: make(map[model.ID][]*inMemoryStatEntry)},
+		incident:         &InMemoryIncident{data: make(map[model.ID]*model.Incident)},
+		maintenance:      &InMemoryMaintenance{data: make(map[model.ID]*model.Maintenance)},
+		silence:          &InMemorySilence{data: make(map[model.ID]*model.Silence)},
+		channel:          &InMemoryChannel{data: make(map[model.ID]*model.Channel)},
+		escalationPolicy: &InMemoryEscalationPolicy{data: make(map[model.ID]*model.EscalationPolicy)},
+		escalation:       &InMemoryEscalation{data: make(map[model.ID]*model.Escalation)},
+		routingRule:      &InMemoryRoutingRule{data: make(map[model.ID]*model.RoutingRule)},
+		notificationJob:  &InMemoryNotificationJob{data: make(map[model.ID]*model.NotificationJob)},
+		logger:           logger,
 	}
 }
 
@@ -37,6 +57,42 @@ func (s *InMemoryStore) Alert() Alert {
 	return s.alert
 }
 
+func (s *InMemoryStore) Stat() Stat {
+	return s.stat
+}
+
+func (s *InMemoryStore) Incident() Incident {
+	return s.incident
+}
+
+func (s *InMemoryStore) Maintenance() Maintenance {
+	return s.maintenance
+}
+
+func (s *InMemoryStore) Silence() Silence {
+	return s.silence
+}
+
+func (s *InMemoryStore) Channel() Channel {
+	return s.channel
+}
+
+func (s *InMemoryStore) EscalationPolicy() EscalationPolicy {
+	return s.escalationPolicy
+}
+
+func (s *InMemoryStore) Escalation() Escalation {
+	return s.escalation
+}
+
+func (s *InMemoryStore) RoutingRule() RoutingRule {
+	return s.routingRule
+}
+
+func (s *InMemoryStore) NotificationJob() NotificationJob {
+	return s.notificationJob
+}
+
 type idGen int
 
 func (ign *idGen) newId() model.ID {
@@ -82,6 +138,16 @@ func (u *InMemoryUser) Add(_ context.Context, user *model.User) error {
 	return nil
 }
 
+func (u *InMemoryUser) SetTimeZone(_ context.Context, id model.ID, timeZone string) (*model.User, error) {
+	user, ok := u.data[id]
+	if !ok {
+		return nil, NewNotFoundError("user", "id", id)
+	}
+
+	user.TimeZone = timeZone
+	return user, nil
+}
+
 type InMemoryUrl struct {
 	idGen
 	data map[model.ID][]*model.URL // user id -> urls
@@ -132,7 +198,7 @@ func (u *InMemoryUrl) GetDayStats(_ context.Context, userId model.ID, id model.I
 	return nil, NewNotFoundError("url", "id", id)
 }
 
-func (u *InMemoryUrl) UpdateStat(_ context.Context, userId model.ID, id model.ID, stat model.DayStat) (*model.URL, model.DayStat, error) {
+func (u *InMemoryUrl) UpdateStat(_ context.Context, userId model.ID, id model.ID, stat model.DayStat, checkedAt time.Time) (*model.URL, model.DayStat, error) {
 
 	urls, ok := u.data[userId]
 	if !ok {
@@ -145,6 +211,8 @@ func (u *InMemoryUrl) UpdateStat(_ context.Context, userId model.ID, id model.ID
 			continue
 		}
 
+		url.LastCheckedAt = &checkedAt
+
 		// find day stat among url day stats
 		for _, ds := range url.DayStats {
 			// apply change
@@ -162,6 +230,17 @@ func (u *InMemoryUrl) UpdateStat(_ context.Context, userId model.ID, id model.ID
 	return nil, model.DayStat{}, NewNotFoundError("url", "id", id)
 }
 
+func (u *InMemoryUrl) SetPaused(_ context.Context, userId model.ID, id model.ID, paused bool) (*model.URL, error) {
+	for _, url := range u.data[userId] {
+		if url.Id == id {
+			url.Paused = paused
+			return url, nil
+		}
+	}
+
+	return nil, NewNotFoundError("url", "id", id)
+}
+
 func (u *InMemoryUrl) ForAll(_ context.Context, callBack func(model.URL)) error {
 	for _, urls := range u.data {
 		for _, url := range urls {
@@ -196,17 +275,25 @@ func (u *InMemoryUrl) ListenForChanges(_ context.Context) (<-chan UrlChangeEvent
 	return out, nil
 }
 
+// InMemoryAlert is guarded by a mutex, because the state of an alert is checked and changed at once
 type InMemoryAlert struct {
 	idGen
+	mu   sync.Mutex
 	data map[model.ID][]*model.Alert // url id -> alerts
 }
 
 func (a *InMemoryAlert) GetByUrlId(_ context.Context, urlId model.ID) ([]*model.Alert, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	alerts := a.data[urlId]
 	return alerts, nil
 }
 
 func (a *InMemoryAlert) Add(_ context.Context, alert *model.Alert) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	alert.Id = a.newId()
 
 	alerts := a.data[alert.UrlId]
@@ -214,3 +301,661 @@ func (a *InMemoryAlert) Add(_ context.Context, alert *model.Alert) error {
 
 	return nil
 }
+
+func (a *InMemoryAlert) GetByUserId(_ context.Context, userId model.ID, filter AlertFilter, after *model.AlertCursor, limit int) ([]*model.Alert, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := make([]*model.Alert, 0)
+	for _, alerts := range a.data {
+		for _, alert := range alerts {
+			if alert.UserId != userId || !filter.Match(alert) {
+				continue
+			}
+
+			if after != nil && !comesAfter(*after, model.CursorOf(alert)) {
+				continue
+			}
+
+			result = append(result, alert)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return comesAfter(model.CursorOf(result[i]), model.CursorOf(result[j]))
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+// reports whether the alert of b comes after the alert of a in the latest-first order
+func comesAfter(a, b model.AlertCursor) bool {
+	if !a.IssuedAt.Equal(b.IssuedAt) {
+		return b.IssuedAt.Before(a.IssuedAt)
+	}
+
+	// ids are sequential numbers, so longer ids are newer
+	if len(a.Id) != len(b.Id) {
+		return len(b.Id) < len(a.Id)
+	}
+	return b.Id < a.Id
+}
+
+func (a *InMemoryAlert) find(userId model.ID, id model.ID) (*model.Alert, error) {
+	for _, alerts := range a.data {
+		for _, alert := range alerts {
+			if alert.Id == id && alert.UserId == userId {
+				return alert, nil
+			}
+		}
+	}
+
+	return nil, NewNotFoundError("alert", "id", id)
+}
+
+func (a *InMemoryAlert) Get(_ context.Context, userId model.ID, id model.ID) (*model.Alert, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.find(userId, id)
+}
+
+func (a *InMemoryAlert) UpdateState(_ context.Context, userId model.ID, id model.ID, change model.AlertStateChange) (*model.Alert, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	alert, err := a.find(userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !alert.CanChangeTo(change.State) {
+		return nil, ConflictError(fmt.Sprintf("alert is %s and can not be %s", alert.CurrentState(), change.State))
+	}
+
+	alert.Apply(change)
+	return alert, nil
+}
+
+func (a *InMemoryAlert) AddNote(_ context.Context, userId model.ID, id model.ID, note model.AlertNote) (*model.Alert, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	alert, err := a.find(userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	alert.Notes = append(alert.Notes, note)
+	return alert, nil
+}
+
+type inMemoryStatEntry struct {
+	userId model.ID
+	stat   model.Stat
+}
+
+type InMemoryStat struct {
+	data map[model.ID][]*inMemoryStatEntry // url id -> stats
+}
+
+func (s *InMemoryStat) Add(_ context.Context, userId model.ID, urlId model.ID, stat model.Stat) (model.Stat, error) {
+	s.removeExpired(urlId, time.Now())
+
+	for _, e := range s.data[urlId] {
+		if e.userId == userId && e.stat.Resolution == stat.Resolution && e.stat.Start.Equal(stat.Start) {
+			e.stat.SuccessCount += stat.SuccessCount
+			e.stat.FailureCount += stat.FailureCount
+			return e.stat, nil
+		}
+	}
+
+	s.data[urlId] = append(s.data[urlId], &inMemoryStatEntry{userId: userId, stat: stat})
+	return stat, nil
+}
+
+func (s *InMemoryStat) Get(_ context.Context, userId model.ID, urlId model.ID, resolution model.Resolution, from time.Time, to time.Time) ([]model.Stat, error) {
+	s.removeExpired(urlId, time.Now())
+
+	result := make([]model.Stat, 0)
+	for _, e := range s.data[urlId] {
+		if e.userId != userId || e.stat.Resolution != resolution {
+			continue
+		}
+
+		if e.stat.Start.Before(from) || !e.stat.Start.Before(to) {
+			continue
+		}
+
+		result = append(result, e.stat)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+
+	return result, nil
+}
+
+// removes the downsampled buckets, same as the ttl index of mongodb
+func (s *InMemoryStat) removeExpired(urlId model.ID, now time.Time) {
+	entries, ok := s.data[urlId]
+	if !ok {
+		return
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.stat.ExpiresAt.IsZero() || e.stat.ExpiresAt.After(now) {
+			kept = append(kept, e)
+		}
+	}
+	s.data[urlId] = kept
+}
+
+type InMemoryIncident struct {
+	idGen
+	data map[model.ID]*model.Incident // incident id -> incident
+}
+
+func (i *InMemoryIncident) Add(_ context.Context, incident *model.Incident) error {
+	incident.Id = i.newId()
+
+	stored := *incident
+	i.data[incident.Id] = &stored
+
+	return nil
+}
+
+func (i *InMemoryIncident) Update(_ context.Context, incident *model.Incident) error {
+	if _, ok := i.data[incident.Id]; !ok {
+		return NewNotFoundError("incident", "id", incident.Id)
+	}
+
+	stored := *incident
+	i.data[incident.Id] = &stored
+
+	return nil
+}
+
+func (i *InMemoryIncident) Get(_ context.Context, userId model.ID, id model.ID) (*model.Incident, error) {
+	incident, ok := i.data[id]
+	if !ok || incident.UserId != userId {
+		return nil, NewNotFoundError("incident", "id", id)
+	}
+
+	result := *incident
+	return &result, nil
+}
+
+func (i *InMemoryIncident) GetByUserId(_ context.Context, userId model.ID, filter IncidentFilter) ([]*model.Incident, error) {
+	result := make([]*model.Incident, 0)
+	for _, incident := range i.data {
+		if incident.UserId == userId && filter.Match(incident) {
+			copied := *incident
+			result = append(result, &copied)
+		}
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].StartedAt.After(result[b].StartedAt)
+	})
+
+	return result, nil
+}
+
+func (i *InMemoryIncident) GetOpen(_ context.Context) ([]*model.Incident, error) {
+	result := make([]*model.Incident, 0)
+	for _, incident := range i.data {
+		if incident.State == model.IncidentStateOpen {
+			copied := *incident
+			result = append(result, &copied)
+		}
+	}
+
+	return result, nil
+}
+
+type InMemoryMaintenance struct {
+	idGen
+	data map[model.ID]*model.Maintenance // maintenance id -> maintenance
+}
+
+func (m *InMemoryMaintenance) Add(_ context.Context, maintenance *model.Maintenance) error {
+	maintenance.Id = m.newId()
+	m.data[maintenance.Id] = maintenance
+
+	return nil
+}
+
+func (m *InMemoryMaintenance) GetByUserId(_ context.Context, userId model.ID) ([]*model.Maintenance, error) {
+	result := make([]*model.Maintenance, 0)
+	for _, maintenance := range m.data {
+		if maintenance.UserId == userId {
+			result = append(result, maintenance)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *InMemoryMaintenance) Delete(_ context.Context, userId model.ID, id model.ID) error {
+	maintenance, ok := m.data[id]
+	if !ok || maintenance.UserId != userId {
+		return NewNotFoundError("maintenance", "id", id)
+	}
+
+	delete(m.data, id)
+	return nil
+}
+
+func (m *InMemoryMaintenance) GetCurrent(_ context.Context, now time.Time) ([]*model.Maintenance, error) {
+	result := make([]*model.Maintenance, 0)
+	for _, maintenance := range m.data {
+		if maintenance.IsRecurring() || maintenance.End.After(now) {
+			result = append(result, maintenance)
+		}
+	}
+
+	return result, nil
+}
+
+type InMemorySilence struct {
+	idGen
+	data map[model.ID]*model.Silence // silence id -> silence
+}
+
+func (m *InMemorySilence) Add(_ context.Context, silence *model.Silence) error {
+	silence.Id = m.newId()
+	m.data[silence.Id] = silence
+
+	return nil
+}
+
+func (m *InMemorySilence) GetByUserId(_ context.Context, userId model.ID, now time.Time, withExpired bool) ([]*model.Silence, error) {
+	result := make([]*model.Silence, 0)
+	for _, silence := range m.data {
+		if silence.UserId == userId && (withExpired || !silence.Expired(now)) {
+			result = append(result, silence)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (m *InMemorySilence) Expire(_ context.Context, userId model.ID, id model.ID, at time.Time) (*model.Silence, error) {
+	silence, ok := m.data[id]
+	if !ok || silence.UserId != userId {
+		return nil, NewNotFoundError("silence", "id", id)
+	}
+
+	if silence.EndsAt.After(at) {
+		silence.EndsAt = at
+	}
+
+	return silence, nil
+}
+
+func (m *InMemorySilence) GetActive(_ context.Context, userId model.ID, at time.Time) ([]*model.Silence, error) {
+	result := make([]*model.Silence, 0)
+	for _, silence := range m.data {
+		if silence.UserId == userId && silence.ActiveAt(at) {
+			result = append(result, silence)
+		}
+	}
+
+	return result, nil
+}
+
+type InMemoryChannel struct {
+	idGen
+	data map[model.ID]*model.Channel // channel id -> channel
+}
+
+func (m *InMemoryChannel) Add(_ context.Context, channel *model.Channel) error {
+	channel.Id = m.newId()
+	m.data[channel.Id] = channel
+
+	return nil
+}
+
+func (m *InMemoryChannel) Get(_ context.Context, userId model.ID, id model.ID) (*model.Channel, error) {
+	channel, ok := m.data[id]
+	if !ok || channel.UserId != userId {
+		return nil, NewNotFoundError("channel", "id", id)
+	}
+
+	return channel, nil
+}
+
+func (m *InMemoryChannel) GetByUserId(_ context.Context, userId model.ID) ([]*model.Channel, error) {
+	result := make([]*model.Channel, 0)
+	for _, channel := range m.data {
+		if channel.UserId == userId {
+			result = append(result, channel)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *InMemoryChannel) Delete(_ context.Context, userId model.ID, id model.ID) error {
+	channel, ok := m.data[id]
+	if !ok || channel.UserId != userId {
+		return NewNotFoundError("channel", "id", id)
+	}
+
+	delete(m.data, id)
+	return nil
+}
+
+func (m *InMemoryChannel) SetTemplate(_ context.Context, userId model.ID, id model.ID, template *model.Template) (*model.Channel, error) {
+	channel, ok := m.data[id]
+	if !ok || channel.UserId != userId {
+		return nil, NewNotFoundError("channel", "id", id)
+	}
+
+	channel.Template = template
+	return channel, nil
+}
+
+type InMemoryEscalationPolicy struct {
+	idGen
+	data map[model.ID]*model.EscalationPolicy // policy id -> policy
+}
+
+func (m *InMemoryEscalationPolicy) Add(_ context.Context, policy *model.EscalationPolicy) error {
+	policy.Id = m.newId()
+	m.data[policy.Id] = policy
+
+	return nil
+}
+
+func (m *InMemoryEscalationPolicy) Get(_ context.Context, userId model.ID, id model.ID) (*model.EscalationPolicy, error) {
+	policy, ok := m.data[id]
+	if !ok || policy.UserId != userId {
+		return nil, NewNotFoundError("escalation policy", "id", id)
+	}
+
+	return policy, nil
+}
+
+func (m *InMemoryEscalationPolicy) GetByUserId(_ context.Context, userId model.ID) ([]*model.EscalationPolicy, error) {
+	result := make([]*model.EscalationPolicy, 0)
+	for _, policy := range m.data {
+		if policy.UserId == userId {
+			result = append(result, policy)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *InMemoryEscalationPolicy) Delete(_ context.Context, userId model.ID, id model.ID) error {
+	policy, ok := m.data[id]
+	if !ok || policy.UserId != userId {
+		return NewNotFoundError("escalation policy", "id", id)
+	}
+
+	delete(m.data, id)
+	return nil
+}
+
+type InMemoryEscalation struct {
+	idGen
+	data map[model.ID]*model.Escalation // escalation id -> escalation
+}
+
+func (m *InMemoryEscalation) Add(_ context.Context, escalation *model.Escalation) error {
+	escalation.Id = m.newId()
+	e := *escalation
+	m.data[escalation.Id] = &e
+
+	return nil
+}
+
+func (m *InMemoryEscalation) Update(_ context.Context, escalation *model.Escalation) error {
+	if _, ok := m.data[escalation.Id]; !ok {
+		return NewNotFoundError("escalation", "id", escalation.Id)
+	}
+
+	e := *escalation
+	m.data[escalation.Id] = &e
+	return nil
+}
+
+func (m *InMemoryEscalation) GetDue(_ context.Context, now time.Time) ([]*model.Escalation, error) {
+	result := make([]*model.Escalation, 0)
+	for _, escalation := range m.data {
+		if escalation.State == model.EscalationStateActive && !escalation.NextAt.After(now) {
+			e := *escalation
+			result = append(result, &e)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NextAt.Before(result[j].NextAt)
+	})
+
+	return result, nil
+}
+
+func (m *InMemoryEscalation) GetNext(_ context.Context) (*model.Escalation, error) {
+	var next *model.Escalation
+	for _, escalation := range m.data {
+		if escalation.State != model.EscalationStateActive {
+			continue
+		}
+		if next == nil || escalation.NextAt.Before(next.NextAt) {
+			next = escalation
+		}
+	}
+
+	if next == nil {
+		return nil, nil
+	}
+
+	e := *next
+	return &e, nil
+}
+
+type InMemoryRoutingRule struct {
+	idGen
+	data map[model.ID]*model.RoutingRule // rule id -> rule
+}
+
+func (m *InMemoryRoutingRule) Add(_ context.Context, rule *model.RoutingRule) error {
+	rule.Id = m.newId()
+	m.data[rule.Id] = rule
+
+	return nil
+}
+
+func (m *InMemoryRoutingRule) GetByUserId(_ context.Context, userId model.ID) ([]*model.RoutingRule, error) {
+	result := make([]*model.RoutingRule, 0)
+	for _, rule := range m.data {
+		if rule.UserId == userId {
+			result = append(result, rule)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Order != result[j].Order {
+			return result[i].Order < result[j].Order
+		}
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (m *InMemoryRoutingRule) Delete(_ context.Context, userId model.ID, id model.ID) error {
+	rule, ok := m.data[id]
+	if !ok || rule.UserId != userId {
+		return NewNotFoundError("routing rule", "id", id)
+	}
+
+	delete(m.data, id)
+	return nil
+}
+
+// InMemoryNotificationJob is guarded by a mutex, because jobs are claimed by concurrent workers
+type InMemoryNotificationJob struct {
+	idGen
+	mu   sync.Mutex
+	data map[model.ID]*model.NotificationJob // job id -> job
+}
+
+func (m *InMemoryNotificationJob) Add(_ context.Context, job *model.NotificationJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job.Id = m.newId()
+	j := *job
+	m.data[job.Id] = &j
+
+	return nil
+}
+
+func (m *InMemoryNotificationJob) Update(_ context.Context, job *model.NotificationJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data[job.Id]; !ok {
+		return NewNotFoundError("notification job", "id", job.Id)
+	}
+
+	j := *job
+	m.data[job.Id] = &j
+	return nil
+}
+
+func (m *InMemoryNotificationJob) Get(_ context.Context, userId model.ID, id model.ID) (*model.NotificationJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.data[id]
+	if !ok || job.UserId != userId {
+		return nil, NewNotFoundError("notification job", "id", id)
+	}
+
+	j := *job
+	return &j, nil
+}
+
+func (m *InMemoryNotificationJob) GetByUserId(_ context.Context, userId model.ID, status model.NotificationJobStatus, limit int) ([]*model.NotificationJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]*model.NotificationJob, 0)
+	for _, job := range m.data {
+		if job.UserId == userId && (status == "" || job.Status == status) {
+			j := *job
+			result = append(result, &j)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].Id > result[j].Id
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+func (m *InMemoryNotificationJob) Claim(_ context.Context, now time.Time, lease time.Duration) (*model.NotificationJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next := m.next()
+	if next == nil || next.NextAttemptAt.After(now) {
+		return nil, nil
+	}
+
+	next.Attempts++
+	next.NextAttemptAt = now.Add(lease)
+
+	j := *next
+	return &j, nil
+}
+
+func (m *InMemoryNotificationJob) GetNext(_ context.Context) (*model.NotificationJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next := m.next()
+	if next == nil {
+		return nil, nil
+	}
+
+	j := *next
+	return &j, nil
+}
+
+func (m *InMemoryNotificationJob) GetTriggeredChannelIds(_ context.Context, userId model.ID, alertIds []model.ID) ([]model.ID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts := make(map[model.ID]bool, len(alertIds))
+	for _, id := range alertIds {
+		alerts[id] = true
+	}
+
+	seen := make(map[model.ID]bool)
+	result := make([]model.ID, 0)
+	for _, job := range m.data {
+		if job.UserId != userId || !alerts[job.AlertId] || !job.Type.Triggers() || seen[job.ChannelId] {
+			continue
+		}
+		seen[job.ChannelId] = true
+		result = append(result, job.ChannelId)
+	}
+
+	return result, nil
+}
+
+// returns the pending job with the earliest next attempt
+func (m *InMemoryNotificationJob) next() *model.NotificationJob {
+	var next *model.NotificationJob
+	for _, job := range m.data {
+		if job.Status != model.NotificationJobStatusPending {
+			continue
+		}
+		if next == nil || job.NextAttemptAt.Before(next.NextAttemptAt) {
+			next = job
+		}
+	}
+	return next
+}
+
+func (m *InMemoryNotificationJob) Redrive(_ context.Context, userId model.ID, id model.ID, at time.Time) (*model.NotificationJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.data[id]
+	if !ok || job.UserId != userId || job.Status != model.NotificationJobStatusDead {
+		return nil, NewNotFoundError("dead notification job", "id", id)
+	}
+
+	job.Status = model.NotificationJobStatusPending
+	job.Attempts = 0
+	job.NextAttemptAt = at
+	job.CompletedAt = nil
+
+	j := *job
+	return &j, nil
+}
@@ -3,6 +3,7 @@ package store_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -172,6 +173,7 @@ func TestUpdateStat(t *testing.T) {
 			"1",
 			urlId,
 			model.DayStat{Date: model.Date{Year: 2020, Month: 3, Day: 1}, SuccessCount: 5, FailureCount: 6},
+			time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC),
 		)
 
 		if err != nil {
@@ -200,6 +202,7 @@ func TestUpdateStat(t *testing.T) {
 			"1",
 			urlId,
 			model.DayStat{Date: model.Date{Year: 2020, Month: 3, Day: 1}, SuccessCount: 1, FailureCount: 1},
+			time.Date(2020, 3, 1, 10, 5, 0, 0, time.UTC),
 		)
 
 		if err != nil {
@@ -218,6 +221,10 @@ func TestUpdateStat(t *testing.T) {
 			stat.FailureCount == 7) {
 			t.Fatalf("unexpected stat value: %v", stat)
 		}
+
+		if checkedAt := time.Date(2020, 3, 1, 10, 5, 0, 0, time.UTC); url.LastCheckedAt == nil || !url.LastCheckedAt.Equal(checkedAt) {
+			t.Fatalf("last check is not recorded: %v", url.LastCheckedAt)
+		}
 	}
 }
 
@@ -272,3 +279,633 @@ func TestAddAndGetAlert(t *testing.T) {
 		}
 	}
 }
+
+func TestAddAndGetStats(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+
+	start := time.Date(2020, 3, 1, 10, 5, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Stat().Add(ctx, "1", "1", model.Stat{
+			Resolution:   model.ResolutionMinute,
+			Start:        start,
+			SuccessCount: 1,
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if _, err := s.Stat().Add(ctx, "1", "1", model.Stat{
+		Resolution:   model.ResolutionMinute,
+		Start:        start.Add(time.Minute),
+		FailureCount: 1,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// expired bucket
+	if _, err := s.Stat().Add(ctx, "1", "1", model.Stat{
+		Resolution:   model.ResolutionMinute,
+		Start:        start.Add(2 * time.Minute),
+		FailureCount: 1,
+		ExpiresAt:    time.Now().Add(-time.Second),
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// other resolution
+	if _, err := s.Stat().Add(ctx, "1", "1", model.Stat{
+		Resolution:   model.ResolutionHour,
+		Start:        start.Truncate(time.Hour),
+		SuccessCount: 1,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stats, err := s.Stat().Get(ctx, "1", "1", model.ResolutionMinute, start.Add(-time.Hour), start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stats) != 2 {
+		t.Fatalf("stats list length is not two: %v", stats)
+	}
+
+	if !(stats[0].Start.Equal(start) && stats[0].SuccessCount == 3 && stats[0].FailureCount == 0) {
+		t.Fatalf("unexpected value of first stat: %v", stats[0])
+	}
+
+	if !(stats[1].Start.Equal(start.Add(time.Minute)) && stats[1].SuccessCount == 0 && stats[1].FailureCount == 1) {
+		t.Fatalf("unexpected value of second stat: %v", stats[1])
+	}
+
+	{
+		stats, err := s.Stat().Get(ctx, "2", "1", model.ResolutionMinute, start.Add(-time.Hour), start.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(stats) != 0 {
+			t.Fatalf("stats of other users should not be returned: %v", stats)
+		}
+	}
+}
+
+func TestSetTimeZone(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+
+	if _, err := s.User().SetTimeZone(ctx, "1", "Asia/Tehran"); err == nil {
+		t.Fatal("should throw not found for unknown user")
+	}
+
+	user := &model.User{Username: "meysam", Password: "123456"}
+	if err := s.User().Add(ctx, user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := s.User().SetTimeZone(ctx, user.Id, "Asia/Tehran")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if updated.TimeZone != "Asia/Tehran" {
+		t.Fatalf("time zone was not updated: %v", *updated)
+	}
+}
+
+func TestIncidents(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	resolvedAt := start.Add(time.Hour)
+
+	resolved := &model.Incident{UserId: "1", UrlId: "1", State: model.IncidentStateResolved, StartedAt: start, ResolvedAt: &resolvedAt}
+	open := &model.Incident{UserId: "1", UrlId: "2", State: model.IncidentStateOpen, StartedAt: start.Add(2 * time.Hour)}
+	other := &model.Incident{UserId: "2", UrlId: "3", State: model.IncidentStateOpen, StartedAt: start}
+
+	for _, incident := range []*model.Incident{resolved, open, other} {
+		if err := s.Incident().Add(ctx, incident); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	{
+		incidents, err := s.Incident().GetByUserId(ctx, "1", store.IncidentFilter{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(incidents) != 2 || incidents[0].Id != open.Id || incidents[1].Id != resolved.Id {
+			t.Fatalf("unexpected incidents: %v", incidents)
+		}
+	}
+
+	{
+		from := start.Add(90 * time.Minute)
+		incidents, err := s.Incident().GetByUserId(ctx, "1", store.IncidentFilter{From: &from})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(incidents) != 1 || incidents[0].Id != open.Id {
+			t.Fatalf("unexpected incidents: %v", incidents)
+		}
+	}
+
+	{
+		incidents, err := s.Incident().GetOpen(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(incidents) != 2 {
+			t.Fatalf("open incidents list length is not two: %v", incidents)
+		}
+	}
+
+	if _, err := s.Incident().Get(ctx, "2", open.Id); err == nil {
+		t.Fatal("should not return incidents of other users")
+	}
+
+	open.State = model.IncidentStateResolved
+	if err := s.Incident().Update(ctx, open); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	incident, err := s.Incident().Get(ctx, "1", open.Id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if incident.State != model.IncidentStateResolved {
+		t.Fatalf("incident was not updated: %v", *incident)
+	}
+}
+
+func TestAlertStateAndNotes(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+
+	alert := &model.Alert{UserId: "1", UrlId: "1", State: model.AlertStateOpen}
+	if err := s.Alert().Add(ctx, alert); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := s.Alert().Get(ctx, "2", alert.Id); err == nil {
+		t.Fatal("should not return alerts of other users")
+	}
+
+	at := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	updated, err := s.Alert().UpdateState(ctx, "1", alert.Id, model.AlertStateChange{
+		State:     model.AlertStateAcknowledged,
+		ChangedBy: "1",
+		ChangedAt: at,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if updated.State != model.AlertStateAcknowledged || updated.AcknowledgedBy != "1" || !updated.AcknowledgedAt.Equal(at) {
+		t.Fatalf("unexpected value of alert: %v", *updated)
+	}
+
+	if updated.CanChangeTo(model.AlertStateAcknowledged) || !updated.CanChangeTo(model.AlertStateResolved) {
+		t.Fatalf("unexpected transitions of acknowledged alert")
+	}
+
+	updated, err = s.Alert().AddNote(ctx, "1", alert.Id, model.AlertNote{Text: "looking into it", CreatedBy: "1", CreatedAt: at})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(updated.Notes) != 1 || updated.Notes[0].Text != "looking into it" {
+		t.Fatalf("unexpected notes of alert: %v", updated.Notes)
+	}
+}
+
+func TestAlertStateConflict(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+
+	alert := &model.Alert{UserId: "1", UrlId: "1", State: model.AlertStateOpen}
+	if err := s.Alert().Add(ctx, alert); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	at := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := s.Alert().UpdateState(ctx, "1", alert.Id, model.AlertStateChange{State: model.AlertStateResolved, ChangedBy: "1", ChangedAt: at}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a request that checked the alert before it was resolved
+	_, err := s.Alert().UpdateState(ctx, "1", alert.Id, model.AlertStateChange{State: model.AlertStateAcknowledged, ChangedBy: "2", ChangedAt: at})
+	var conflict store.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+
+	updated, err := s.Alert().Get(ctx, "1", alert.Id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.State != model.AlertStateResolved || updated.AcknowledgedAt != nil {
+		t.Fatalf("unexpected value of alert: %v", *updated)
+	}
+}
+
+func TestGetAlertsByUserIdPaginated(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+
+	base := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 12; i++ {
+		alert := &model.Alert{
+			UserId: "1",
+			UrlId:  model.ID(fmt.Sprint(i % 2)),
+			// two alerts are issued at each time to check the tie-breaker
+			IssuedAt: base.Add(time.Duration(i/2) * time.Minute),
+		}
+		if err := s.Alert().Add(ctx, alert); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := s.Alert().Add(ctx, &model.Alert{UserId: "2", UrlId: "5", IssuedAt: base}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var (
+		after *model.AlertCursor
+		seen  []*model.Alert
+	)
+	for {
+		alerts, err := s.Alert().GetByUserId(ctx, "1", store.AlertFilter{}, after, 5)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		seen = append(seen, alerts...)
+		if len(alerts) < 5 {
+			break
+		}
+
+		cursor := model.CursorOf(alerts[len(alerts)-1])
+		after = &cursor
+	}
+
+	if len(seen) != 12 {
+		t.Fatalf("unexpected number of alerts: %v", len(seen))
+	}
+
+	for i := 1; i < len(seen); i++ {
+		if seen[i].IssuedAt.After(seen[i-1].IssuedAt) {
+			t.Fatalf("alerts are not sorted: %v %v", seen[i-1], seen[i])
+		}
+
+		if seen[i].Id == seen[i-1].Id {
+			t.Fatalf("alert was returned twice: %v", seen[i])
+		}
+	}
+
+	{
+		urlId := model.ID("1")
+		from := base.Add(2 * time.Minute)
+		alerts, err := s.Alert().GetByUserId(ctx, "1", store.AlertFilter{UrlId: &urlId, From: &from}, nil, 100)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(alerts) != 4 {
+			t.Fatalf("unexpected number of filtered alerts: %v", alerts)
+		}
+	}
+}
+
+func TestMaintenanceWindows(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+	now := time.Date(2022, 10, 8, 12, 0, 0, 0, time.UTC)
+
+	oneOff := &model.Maintenance{UserId: "1", UrlId: "1", Start: now.Add(-time.Hour), End: now.Add(-time.Minute)}
+	recurring := &model.Maintenance{UserId: "1", Tag: "db", Cron: "0 11 * * *", Duration: model.Interval{Duration: 2 * time.Hour}}
+	for _, m := range []*model.Maintenance{oneOff, recurring} {
+		if err := s.Maintenance().Add(ctx, m); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	current, err := s.Maintenance().GetCurrent(ctx, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(current) != 1 || current[0].Id != recurring.Id {
+		t.Fatalf("ended window was returned: %v", current)
+	}
+
+	url := &model.URL{Id: "2", UserId: "1", Tags: []string{"db"}}
+	if !recurring.AppliesTo(url) || oneOff.AppliesTo(url) {
+		t.Fatalf("window matched the wrong url")
+	}
+
+	if !recurring.ActiveAt(now) || recurring.ActiveAt(now.Add(time.Hour)) {
+		t.Fatalf("recurring window is not active from 11:00 to 13:00")
+	}
+
+	occurrences, err := recurring.Occurrences(now.Add(-24*time.Hour), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(occurrences) != 2 || !occurrences[1].Start.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("unexpected occurrences: %v", occurrences)
+	}
+
+	compiled, err := recurring.Compile()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !compiled.ActiveAt(now) || compiled.ActiveAt(now.Add(time.Hour)) {
+		t.Fatalf("compiled window is not active from 11:00 to 13:00")
+	}
+
+	// the occurrences of a frequent schedule in a long range are not cut short
+	frequent := &model.Maintenance{UserId: "1", Tag: "db", Cron: "* * * * *", Duration: model.Interval{Duration: time.Minute}}
+	if _, err := frequent.Occurrences(now.Add(-30*24*time.Hour), now); !errors.Is(err, model.ErrTooManyOccurrences) {
+		t.Fatalf("expected too many occurrences, got %v", err)
+	}
+
+	if err := s.Maintenance().Delete(ctx, "2", recurring.Id); err == nil {
+		t.Fatalf("window of another user was deleted")
+	}
+	if err := s.Maintenance().Delete(ctx, "1", recurring.Id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSilences(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+	now := time.Date(2022, 10, 8, 12, 0, 0, 0, time.UTC)
+
+	silence := &model.Silence{
+		UserId:   "1",
+		Matchers: []model.SilenceMatcher{{Name: model.MatcherTag, Value: "db"}},
+		StartsAt: now.Add(-time.Hour),
+		EndsAt:   now.Add(time.Hour),
+	}
+	if err := s.Silence().Add(ctx, silence); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !silence.Matches(&model.URL{UserId: "1", Tags: []string{"db"}}) {
+		t.Fatalf("silence did not match url with tag")
+	}
+	if silence.Matches(&model.URL{UserId: "2", Tags: []string{"db"}}) {
+		t.Fatalf("silence matched url of another user")
+	}
+
+	active, err := s.Silence().GetActive(ctx, "1", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(active) != 1 {
+		t.Fatalf("unexpected active silences: %v", active)
+	}
+
+	expired, err := s.Silence().Expire(ctx, "1", silence.Id, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !expired.EndsAt.Equal(now) {
+		t.Fatalf("silence did not end at expiry: %v", expired.EndsAt)
+	}
+
+	// expiring again does not extend the silence
+	if _, err := s.Silence().Expire(ctx, "1", silence.Id, now.Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !silence.EndsAt.Equal(now) {
+		t.Fatalf("expired silence was changed: %v", silence.EndsAt)
+	}
+
+	for _, withExpired := range []bool{false, true} {
+		silences, err := s.Silence().GetByUserId(ctx, "1", now, withExpired)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(silences) != map[bool]int{false: 0, true: 1}[withExpired] {
+			t.Fatalf("unexpected silences with expired=%v: %v", withExpired, silences)
+		}
+	}
+}
+
+func TestEscalations(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+	issuedAt := time.Date(2022, 10, 8, 12, 0, 0, 0, time.UTC)
+
+	policy := &model.EscalationPolicy{
+		UserId: "1",
+		Steps: []model.EscalationStep{
+			{After: model.Interval{}, ChannelIds: []model.ID{"1"}},
+			{After: model.Interval{Duration: 10 * time.Minute}, ChannelIds: []model.ID{"2"}},
+		},
+	}
+	if err := s.EscalationPolicy().Add(ctx, policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	escalation := &model.Escalation{UserId: "1", AlertId: "1", PolicyId: policy.Id, NextAt: issuedAt, State: model.EscalationStateActive}
+	if err := s.Escalation().Add(ctx, escalation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	due, err := s.Escalation().GetDue(ctx, issuedAt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(due) != 1 {
+		t.Fatalf("unexpected due escalations: %v", due)
+	}
+
+	due[0].Advance(policy, issuedAt)
+	if err := s.Escalation().Update(ctx, due[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if due, _ := s.Escalation().GetDue(ctx, issuedAt.Add(time.Minute)); len(due) != 0 {
+		t.Fatalf("escalation is due before its next step: %v", due)
+	}
+
+	next, err := s.Escalation().GetNext(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next == nil || next.Step != 1 || !next.NextAt.Equal(issuedAt.Add(10*time.Minute)) {
+		t.Fatalf("unexpected next escalation: %v", next)
+	}
+
+	next.Advance(policy, issuedAt)
+	if next.State != model.EscalationStateCompleted {
+		t.Fatalf("escalation was not completed after the last step: %v", next)
+	}
+	if err := s.Escalation().Update(ctx, next); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next, _ := s.Escalation().GetNext(ctx); next != nil {
+		t.Fatalf("completed escalation was returned: %v", next)
+	}
+}
+
+func TestRoutingRules(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+
+	rules := []*model.RoutingRule{
+		{UserId: "1", Order: 2, ChannelIds: []model.ID{"3"}},
+		{UserId: "1", Order: 1, Tags: []string{"db"}, Severities: []model.AlertSeverity{model.AlertSeverityCritical}, ChannelIds: []model.ID{"1", "2"}, Stop: true},
+		{UserId: "1", Order: 0, Types: []model.AlertType{model.AlertTypeDown}, ChannelIds: []model.ID{"2"}},
+		{UserId: "2", ChannelIds: []model.ID{"4"}},
+	}
+	for _, rule := range rules {
+		if err := s.RoutingRule().Add(ctx, rule); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	stored, err := s.RoutingRule().GetByUserId(ctx, "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stored) != 3 || stored[0].Order != 0 || stored[2].Order != 2 {
+		t.Fatalf("rules are not sorted by order: %v", stored)
+	}
+
+	url := &model.URL{Id: "1", UserId: "1", Tags: []string{"db"}}
+
+	// the second rule stops the evaluation
+	matched, channels := model.Route(stored, url, model.AlertTypeDown, model.AlertSeverityCritical)
+	if len(matched) != 2 || fmt.Sprint(channels) != "[2 1]" {
+		t.Fatalf("unexpected routing of a critical down alert: %v %v", matched, channels)
+	}
+
+	matched, channels = model.Route(stored, url, model.AlertTypeRecovered, model.AlertSeverityInfo)
+	if len(matched) != 1 || fmt.Sprint(channels) != "[3]" {
+		t.Fatalf("unexpected routing of a recovery: %v %v", matched, channels)
+	}
+}
+
+func TestNotificationJobs(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+	now := time.Date(2022, 10, 8, 12, 0, 0, 0, time.UTC)
+
+	for i, at := range []time.Time{now.Add(time.Minute), now} {
+		job := &model.NotificationJob{
+			UserId:        "1",
+			ChannelId:     model.ID(fmt.Sprint(i + 1)),
+			Status:        model.NotificationJobStatusPending,
+			NextAttemptAt: at,
+			CreatedAt:     now,
+		}
+		if err := s.NotificationJob().Add(ctx, job); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	job, err := s.NotificationJob().Claim(ctx, now, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job == nil || job.ChannelId != "2" || job.Attempts != 1 {
+		t.Fatalf("unexpected claimed job: %v", job)
+	}
+
+	// the claimed job is leased and the other one is not due yet
+	if job, _ := s.NotificationJob().Claim(ctx, now, 5*time.Minute); job != nil {
+		t.Fatalf("claimed a job that is not due: %v", job)
+	}
+
+	job.Fail("unexpected status code 500", now, now.Add(time.Second), 1)
+	if job.Status != model.NotificationJobStatusDead {
+		t.Fatalf("job should be dead after its last attempt: %v", job)
+	}
+	if err := s.NotificationJob().Update(ctx, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dead, err := s.NotificationJob().GetByUserId(ctx, "1", model.NotificationJobStatusDead, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dead) != 1 || dead[0].Id != job.Id {
+		t.Fatalf("unexpected dead jobs: %v", dead)
+	}
+
+	if _, err := s.NotificationJob().Redrive(ctx, "2", job.Id, now); err == nil {
+		t.Fatalf("re-drove a job of another user")
+	}
+
+	redriven, err := s.NotificationJob().Redrive(ctx, "1", job.Id, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if redriven.Status != model.NotificationJobStatusPending || redriven.Attempts != 0 || redriven.CompletedAt != nil {
+		t.Fatalf("unexpected re-driven job: %v", redriven)
+	}
+
+	if _, err := s.NotificationJob().Redrive(ctx, "1", job.Id, now); err == nil {
+		t.Fatalf("re-drove a pending job")
+	}
+
+	next, err := s.NotificationJob().GetNext(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next == nil || next.Id != job.Id {
+		t.Fatalf("unexpected next job: %v", next)
+	}
+}
+
+func TestTriggeredChannels(t *testing.T) {
+	s := store.NewInMemoryStore(zap.NewNop())
+	ctx := context.Background()
+	now := time.Date(2022, 10, 8, 12, 0, 0, 0, time.UTC)
+
+	jobs := []struct {
+		userId    model.ID
+		alertId   model.ID
+		channelId model.ID
+		typ       model.AlertType
+	}{
+		{"1", "a", "routed", model.AlertTypeDown},
+		{"1", "a", "escalated", model.AlertTypeDown},
+		{"1", "b", "routed", model.AlertTypeFlapping},
+		// follow-ups do not raise the alert on their channel
+		{"1", "a", "recovery", model.AlertTypeRecovered},
+		{"1", "c", "other-alert", model.AlertTypeDown},
+		{"2", "a", "other-user", model.AlertTypeDown},
+	}
+	for _, j := range jobs {
+		alert := &model.Alert{Id: j.alertId, UserId: j.userId, UrlId: "1"}
+		if err := s.NotificationJob().Add(ctx, model.NewNotificationJob(j.channelId, alert, j.typ, model.SeverityOf(j.typ), nil, now)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	channelIds, err := s.NotificationJob().GetTriggeredChannelIds(ctx, "1", []model.ID{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := make(map[model.ID]bool)
+	for _, id := range channelIds {
+		found[id] = true
+	}
+	if len(channelIds) != 2 || !found["routed"] || !found["escalated"] {
+		t.Fatalf("unexpected triggered channels: %v", channelIds)
+	}
+}
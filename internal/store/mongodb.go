@@ -16,20 +16,38 @@ import (
 )
 
 type MongodbStore struct {
-	db     *mongo.Database
-	logger *zap.Logger
-	user   *MongodbUser
-	url    *MongodbUrl
-	alert  *MongodbAlert
+	db               *mongo.Database
+	logger           *zap.Logger
+	user             *MongodbUser
+	url              *MongodbUrl
+	alert            *MongodbAlert
+	stat             *MongodbStat
+	incident         *MongodbIncident
+	maintenance      *MongodbMaintenance
+	silence          *MongodbSilence
+	channel          *MongodbChannel
+	escalationPolicy *MongodbEscalationPolicy
+	escalation       *MongodbEscalation
+	routingRule      *MongodbRoutingRule
+	notificationJob  *MongodbNotificationJob
 }
 
 func NewMongodbStore(db *mongo.Database, cfg db.Config, logger *zap.Logger) Store {
 	return &MongodbStore{
-		db:     db,
-		logger: logger,
-		user:   &MongodbUser{db.Collection(cfg.UserCollection)},
-		url:    &MongodbUrl{coll: db.Collection(cfg.UrlCollection), events: db.Collection(cfg.UrlEventCollection), logger: logger.Named("url")},
-		alert:  &MongodbAlert{db.Collection(cfg.AlertCollection)},
+		db:               db,
+		logger:           logger,
+		user:             &MongodbUser{db.Collection(cfg.UserCollection)},
+		url:              &MongodbUrl{coll: db.Collection(cfg.UrlCollection), events: db.Collection(cfg.UrlEventCollection), logger: logger.Named("url")},
+		alert:            &MongodbAlert{db.Collection(cfg.AlertCollection)},
+		stat:             &MongodbStat{db.Collection(cfg.StatCollection)},
+		incident:         &MongodbIncident{db.Collection(cfg.IncidentCollection)},
+		maintenance:      &MongodbMaintenance{db.Collection(cfg.MaintenanceCollection)},
+		silence:          &MongodbSilence{db.Collection(cfg.SilenceCollection)},
+		channel:          &MongodbChannel{db.Collection(cfg.ChannelCollection)},
+		escalationPolicy: &MongodbEscalationPolicy{db.Collection(cfg.EscalationPolicyCollection)},
+		escalation:       &MongodbEscalation{db.Collection(cfg.EscalationCollection)},
+		routingRule:      &MongodbRoutingRule{db.Collection(cfg.RoutingRuleCollection)},
+		notificationJob:  &MongodbNotificationJob{db.Collection(cfg.NotificationJobCollection)},
 	}
 }
 
@@ -45,6 +63,42 @@ func (s *MongodbStore) Alert() Alert {
 	return s.alert
 }
 
+func (s *MongodbStore) Stat() Stat {
+	return s.stat
+}
+
+func (s *MongodbStore) Incident() Incident {
+	return s.incident
+}
+
+func (s *MongodbStore) Maintenance() Maintenance {
+	return s.maintenance
+}
+
+func (s *MongodbStore) Silence() Silence {
+	return s.silence
+}
+
+func (s *MongodbStore) Channel() Channel {
+	return s.channel
+}
+
+func (s *MongodbStore) EscalationPolicy() EscalationPolicy {
+	return s.escalationPolicy
+}
+
+func (s *MongodbStore) Escalation() Escalation {
+	return s.escalation
+}
+
+func (s *MongodbStore) RoutingRule() RoutingRule {
+	return s.routingRule
+}
+
+func (s *MongodbStore) NotificationJob() NotificationJob {
+	return s.notificationJob
+}
+
 type MongodbUser struct {
 	coll *mongo.Collection
 }
@@ -108,6 +162,34 @@ func (m *MongodbUser) GetByUsername(ctx context.Context, username string) (*mode
 	return &user, nil
 }
 
+func (m *MongodbUser) SetTimeZone(ctx context.Context, id model.ID, timeZone string) (*model.User, error) {
+	r := m.coll.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id.ObjectId()},
+		bson.M{
+			"$set": bson.M{
+				"time_zone": timeZone,
+			},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, NewNotFoundError("user", "id", id)
+		}
+
+		return nil, fmt.Errorf("error updating user: %w", r.Err())
+	}
+
+	var user model.User
+	if err := r.Decode(&user); err != nil {
+		return nil, fmt.Errorf("could not decode result into user: %w", err)
+	}
+
+	return &user, nil
+}
+
 type MongodbUrl struct {
 	coll   *mongo.Collection
 	events *mongo.Collection
@@ -267,7 +349,7 @@ func (m *MongodbUrl) ListenForChanges(ctx context.Context) (<-chan UrlChangeEven
 	return out, nil
 }
 
-func (m *MongodbUrl) UpdateStat(ctx context.Context, userId model.ID, id model.ID, stat model.DayStat) (*model.URL, model.DayStat, error) {
+func (m *MongodbUrl) UpdateStat(ctx context.Context, userId model.ID, id model.ID, stat model.DayStat, checkedAt time.Time) (*model.URL, model.DayStat, error) {
 	r := m.coll.FindOneAndUpdate(
 		ctx,
 		bson.M{
@@ -284,6 +366,9 @@ func (m *MongodbUrl) UpdateStat(ctx context.Context, userId model.ID, id model.I
 				"day_stats.$.success_count": stat.SuccessCount,
 				"day_stats.$.failure_count": stat.FailureCount,
 			},
+			"$set": bson.M{
+				"last_checked_at": checkedAt,
+			},
 		},
 		options.FindOneAndUpdate().SetReturnDocument(options.After),
 	)
@@ -295,7 +380,7 @@ func (m *MongodbUrl) UpdateStat(ctx context.Context, userId model.ID, id model.I
 		}
 
 		// no stat found, create a new one
-		return m.appendStat(ctx, id, userId, stat)
+		return m.appendStat(ctx, id, userId, stat, checkedAt)
 	}
 
 	var url model.URL
@@ -311,7 +396,46 @@ func (m *MongodbUrl) UpdateStat(ctx context.Context, userId model.ID, id model.I
 	return &url, *updatedStat, nil
 }
 
-func (m *MongodbUrl) appendStat(ctx context.Context, id model.ID, userId model.ID, stat model.DayStat) (*model.URL, model.DayStat, error) {
+func (m *MongodbUrl) SetPaused(ctx context.Context, userId model.ID, id model.ID, paused bool) (*model.URL, error) {
+	r := m.coll.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+		bson.M{
+			"$set": bson.M{
+				"paused": paused,
+			},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, NewNotFoundError("url", "id", id)
+		}
+
+		return nil, fmt.Errorf("error updating url: %w", r.Err())
+	}
+
+	var url model.URL
+	if err := r.Decode(&url); err != nil {
+		return nil, fmt.Errorf("could not decode result into url: %w", err)
+	}
+
+	if _, err := m.events.InsertOne(ctx, UrlChangeEvent{
+		Url:       url,
+		Operation: UrlChangeOperationUpdate,
+		Timestamp: time.Now(),
+	}); err != nil {
+		m.logger.Error("could not insert url change event", zap.Error(err), zap.Any("url", url))
+	}
+
+	return &url, nil
+}
+
+func (m *MongodbUrl) appendStat(ctx context.Context, id model.ID, userId model.ID, stat model.DayStat, checkedAt time.Time) (*model.URL, model.DayStat, error) {
 	r := m.coll.FindOneAndUpdate(
 		ctx,
 		bson.M{
@@ -322,6 +446,9 @@ func (m *MongodbUrl) appendStat(ctx context.Context, id model.ID, userId model.I
 			"$push": bson.M{
 				"day_stats": stat,
 			},
+			"$set": bson.M{
+				"last_checked_at": checkedAt,
+			},
 		},
 	)
 
@@ -355,6 +482,132 @@ func (m *MongodbAlert) Add(ctx context.Context, alert *model.Alert) error {
 	return nil
 }
 
+func (m *MongodbAlert) Get(ctx context.Context, userId model.ID, id model.ID) (*model.Alert, error) {
+	r := m.coll.FindOne(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+	)
+
+	return decodeAlert(r, id)
+}
+
+func (m *MongodbAlert) UpdateState(ctx context.Context, userId model.ID, id model.ID, change model.AlertStateChange) (*model.Alert, error) {
+	set := bson.M{"state": change.State}
+	switch change.State {
+	case model.AlertStateAcknowledged:
+		set["acknowledged_at"] = change.ChangedAt
+		set["acknowledged_by"] = change.ChangedBy
+	case model.AlertStateResolved:
+		set["resolved_at"] = change.ChangedAt
+		set["resolved_by"] = change.ChangedBy
+	}
+
+	// alerts stored before alert states were added are open
+	from := bson.A{}
+	for _, s := range model.StatesBefore(change.State) {
+		from = append(from, s)
+		if s == model.AlertStateOpen {
+			from = append(from, nil, "")
+		}
+	}
+
+	r := m.coll.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+			"state":   bson.M{"$in": from},
+		},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	if r.Err() == mongo.ErrNoDocuments {
+		// the alert does not exist, or another request changed its state
+		alert, err := m.Get(ctx, userId, id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ConflictError(fmt.Sprintf("alert is %s and can not be %s", alert.CurrentState(), change.State))
+	}
+
+	return decodeAlert(r, id)
+}
+
+func (m *MongodbAlert) AddNote(ctx context.Context, userId model.ID, id model.ID, note model.AlertNote) (*model.Alert, error) {
+	r := m.coll.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+		bson.M{
+			"$push": bson.M{
+				"notes": note,
+			},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	return decodeAlert(r, id)
+}
+
+func (m *MongodbAlert) GetByUserId(ctx context.Context, userId model.ID, filter AlertFilter, after *model.AlertCursor, limit int) ([]*model.Alert, error) {
+	q := filter.query(userId)
+	if after != nil {
+		q = bson.M{
+			"$and": bson.A{
+				q,
+				bson.M{
+					"$or": bson.A{
+						bson.M{"issued_at": bson.M{"$lt": after.IssuedAt}},
+						bson.M{"issued_at": after.IssuedAt, "_id": bson.M{"$lt": after.Id.ObjectId()}},
+					},
+				},
+			},
+		}
+	}
+
+	cursor, err := m.coll.Find(
+		ctx,
+		q,
+		options.Find().
+			SetSort(bson.D{{Key: "issued_at", Value: -1}, {Key: "_id", Value: -1}}).
+			SetLimit(int64(limit)),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading from alert collection: %w", err)
+	}
+
+	all := make([]*model.Alert, 0)
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, fmt.Errorf("error decoding all results to alert: %w", err)
+	}
+
+	return all, nil
+}
+
+func decodeAlert(r *mongo.SingleResult, id model.ID) (*model.Alert, error) {
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, NewNotFoundError("alert", "id", id)
+		}
+
+		return nil, fmt.Errorf("error getting alert: %w", r.Err())
+	}
+
+	var alert model.Alert
+	if err := r.Decode(&alert); err != nil {
+		return nil, fmt.Errorf("could not decode result into alert: %w", err)
+	}
+
+	return &alert, nil
+}
+
 func (m *MongodbAlert) GetByUrlId(ctx context.Context, id model.ID) ([]*model.Alert, error) {
 	cursor, err := m.coll.Find(
 		ctx,
@@ -375,22 +628,839 @@ func (m *MongodbAlert) GetByUrlId(ctx context.Context, id model.ID) ([]*model.Al
 	return all, nil
 }
 
-func findStat(stats []*model.DayStat, date model.Date) *model.DayStat {
-	for _, stat := range stats {
-		if stat.Date == date {
-			return stat
+type MongodbStat struct {
+	coll *mongo.Collection
+}
+
+func (m *MongodbStat) Add(ctx context.Context, userId model.ID, urlId model.ID, stat model.Stat) (model.Stat, error) {
+	update := bson.M{
+		"$inc": bson.M{
+			"success_count": stat.SuccessCount,
+			"failure_count": stat.FailureCount,
+		},
+	}
+
+	// expired buckets are removed by the ttl index on "expires_at"
+	if !stat.ExpiresAt.IsZero() {
+		update["$setOnInsert"] = bson.M{
+			"expires_at": stat.ExpiresAt,
 		}
 	}
 
+	r := m.coll.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"user_id":    userId,
+			"url_id":     urlId,
+			"resolution": stat.Resolution,
+			"start":      stat.Start,
+		},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	)
+
+	if r.Err() != nil {
+		return model.Stat{}, fmt.Errorf("error updating stat: %w", r.Err())
+	}
+
+	var updated model.Stat
+	if err := r.Decode(&updated); err != nil {
+		return model.Stat{}, fmt.Errorf("could not decode result into stat: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (m *MongodbStat) Get(ctx context.Context, userId model.ID, urlId model.ID, resolution model.Resolution, from time.Time, to time.Time) ([]model.Stat, error) {
+	cursor, err := m.coll.Find(
+		ctx,
+		bson.M{
+			"user_id":    userId,
+			"url_id":     urlId,
+			"resolution": resolution,
+			"start": bson.M{
+				"$gte": from,
+				"$lt":  to,
+			},
+		},
+		options.Find().SetSort(bson.D{{Key: "start", Value: 1}}),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading from stat collection: %w", err)
+	}
+
+	all := make([]model.Stat, 0)
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, fmt.Errorf("error decoding all results to stat: %w", err)
+	}
+
+	return all, nil
+}
+
+type MongodbIncident struct {
+	coll *mongo.Collection
+}
+
+func (m *MongodbIncident) Add(ctx context.Context, incident *model.Incident) error {
+	r, err := m.coll.InsertOne(ctx, incident.NoId())
+	if err != nil {
+		return fmt.Errorf("error inserting incident: %w", err)
+	}
+
+	incident.Id = model.ParseIdFromObjectId(r.InsertedID.(primitive.ObjectID))
+
 	return nil
 }
 
-func filterStats(stats []*model.DayStat, pick func(model.Date) bool) []model.DayStat {
-	filtered := make([]model.DayStat, 0, len(stats)/2)
-	for _, stat := range stats {
-		if pick(stat.Date) {
-			filtered = append(filtered, *stat)
+func (m *MongodbIncident) Update(ctx context.Context, incident *model.Incident) error {
+	r, err := m.coll.ReplaceOne(
+		ctx,
+		bson.M{"_id": incident.Id.ObjectId()},
+		incident.NoId(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("error updating incident: %w", err)
+	}
+
+	if r.MatchedCount == 0 {
+		return NewNotFoundError("incident", "id", incident.Id)
+	}
+
+	return nil
+}
+
+func (m *MongodbIncident) Get(ctx context.Context, userId model.ID, id model.ID) (*model.Incident, error) {
+	r := m.coll.FindOne(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+	)
+
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, NewNotFoundError("incident", "id", id)
 		}
+
+		return nil, fmt.Errorf("error getting incident: %w", r.Err())
 	}
-	return filtered
+
+	var incident model.Incident
+	if err := r.Decode(&incident); err != nil {
+		return nil, fmt.Errorf("could not decode result into incident: %w", err)
+	}
+
+	return &incident, nil
+}
+
+func (m *MongodbIncident) GetByUserId(ctx context.Context, userId model.ID, filter IncidentFilter) ([]*model.Incident, error) {
+	cursor, err := m.coll.Find(
+		ctx,
+		filter.query(userId),
+		options.Find().SetSort(bson.D{{Key: "started_at", Value: -1}}),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading from incident collection: %w", err)
+	}
+
+	all := make([]*model.Incident, 0)
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, fmt.Errorf("error decoding all results to incident: %w", err)
+	}
+
+	return all, nil
+}
+
+func (m *MongodbIncident) GetOpen(ctx context.Context) ([]*model.Incident, error) {
+	cursor, err := m.coll.Find(
+		ctx,
+		bson.M{
+			"state": model.IncidentStateOpen,
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading from incident collection: %w", err)
+	}
+
+	all := make([]*model.Incident, 0)
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, fmt.Errorf("error decoding all results to incident: %w", err)
+	}
+
+	return all, nil
+}
+
+type MongodbMaintenance struct {
+	coll *mongo.Collection
+}
+
+func (m *MongodbMaintenance) Add(ctx context.Context, maintenance *model.Maintenance) error {
+	r, err := m.coll.InsertOne(ctx, maintenance.NoId())
+	if err != nil {
+		return fmt.Errorf("error inserting maintenance: %w", err)
+	}
+
+	maintenance.Id = model.ParseIdFromObjectId(r.InsertedID.(primitive.ObjectID))
+
+	return nil
+}
+
+func (m *MongodbMaintenance) GetByUserId(ctx context.Context, userId model.ID) ([]*model.Maintenance, error) {
+	return m.find(ctx, bson.M{"user_id": userId})
+}
+
+func (m *MongodbMaintenance) Delete(ctx context.Context, userId model.ID, id model.ID) error {
+	r, err := m.coll.DeleteOne(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("error deleting maintenance: %w", err)
+	}
+
+	if r.DeletedCount == 0 {
+		return NewNotFoundError("maintenance", "id", id)
+	}
+
+	return nil
+}
+
+func (m *MongodbMaintenance) GetCurrent(ctx context.Context, now time.Time) ([]*model.Maintenance, error) {
+	return m.find(ctx, bson.M{
+		"$or": bson.A{
+			bson.M{"cron": bson.M{"$nin": bson.A{nil, ""}}},
+			bson.M{"end": bson.M{"$gt": now}},
+		},
+	})
+}
+
+func (m *MongodbMaintenance) find(ctx context.Context, filter bson.M) ([]*model.Maintenance, error) {
+	cursor, err := m.coll.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error reading from maintenance collection: %w", err)
+	}
+
+	all := make([]*model.Maintenance, 0)
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, fmt.Errorf("error decoding all results to maintenance: %w", err)
+	}
+
+	return all, nil
+}
+
+type MongodbSilence struct {
+	coll *mongo.Collection
+}
+
+func (m *MongodbSilence) Add(ctx context.Context, silence *model.Silence) error {
+	r, err := m.coll.InsertOne(ctx, silence.NoId())
+	if err != nil {
+		return fmt.Errorf("error inserting silence: %w", err)
+	}
+
+	silence.Id = model.ParseIdFromObjectId(r.InsertedID.(primitive.ObjectID))
+
+	return nil
+}
+
+func (m *MongodbSilence) GetByUserId(ctx context.Context, userId model.ID, now time.Time, withExpired bool) ([]*model.Silence, error) {
+	filter := bson.M{"user_id": userId}
+	if !withExpired {
+		filter["ends_at"] = bson.M{"$gt": now}
+	}
+
+	return m.find(ctx, filter)
+}
+
+func (m *MongodbSilence) Expire(ctx context.Context, userId model.ID, id model.ID, at time.Time) (*model.Silence, error) {
+	filter := bson.M{
+		"_id":     id.ObjectId(),
+		"user_id": userId,
+	}
+
+	// the end of an already ended silence is not changed
+	_, err := m.coll.UpdateOne(
+		ctx,
+		bson.M{"$and": bson.A{filter, bson.M{"ends_at": bson.M{"$gt": at}}}},
+		bson.M{"$set": bson.M{"ends_at": at}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error expiring silence: %w", err)
+	}
+
+	var silence model.Silence
+	if err := m.coll.FindOne(ctx, filter).Decode(&silence); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, NewNotFoundError("silence", "id", id)
+		}
+		return nil, fmt.Errorf("error finding silence: %w", err)
+	}
+
+	return &silence, nil
+}
+
+func (m *MongodbSilence) GetActive(ctx context.Context, userId model.ID, at time.Time) ([]*model.Silence, error) {
+	return m.find(ctx, bson.M{
+		"user_id":   userId,
+		"starts_at": bson.M{"$lte": at},
+		"ends_at":   bson.M{"$gt": at},
+	})
+}
+
+func (m *MongodbSilence) find(ctx context.Context, filter bson.M) ([]*model.Silence, error) {
+	cursor, err := m.coll.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("error reading from silence collection: %w", err)
+	}
+
+	all := make([]*model.Silence, 0)
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, fmt.Errorf("error decoding all results to silence: %w", err)
+	}
+
+	return all, nil
+}
+
+type MongodbChannel struct {
+	coll *mongo.Collection
+}
+
+func (m *MongodbChannel) Add(ctx context.Context, channel *model.Channel) error {
+	r, err := m.coll.InsertOne(ctx, channel.NoId())
+	if err != nil {
+		return fmt.Errorf("error inserting channel: %w", err)
+	}
+
+	channel.Id = model.ParseIdFromObjectId(r.InsertedID.(primitive.ObjectID))
+
+	return nil
+}
+
+func (m *MongodbChannel) Get(ctx context.Context, userId model.ID, id model.ID) (*model.Channel, error) {
+	r := m.coll.FindOne(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+	)
+
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, NewNotFoundError("channel", "id", id)
+		}
+
+		return nil, fmt.Errorf("error getting channel: %w", r.Err())
+	}
+
+	var channel model.Channel
+	if err := r.Decode(&channel); err != nil {
+		return nil, fmt.Errorf("could not decode result into channel: %w", err)
+	}
+
+	return &channel, nil
+}
+
+func (m *MongodbChannel) GetByUserId(ctx context.Context, userId model.ID) ([]*model.Channel, error) {
+	cursor, err := m.coll.Find(ctx, bson.M{"user_id": userId})
+	if err != nil {
+		return nil, fmt.Errorf("error reading from channel collection: %w", err)
+	}
+
+	all := make([]*model.Channel, 0)
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, fmt.Errorf("error decoding all results to channel: %w", err)
+	}
+
+	return all, nil
+}
+
+func (m *MongodbChannel) Delete(ctx context.Context, userId model.ID, id model.ID) error {
+	r, err := m.coll.DeleteOne(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("error deleting channel: %w", err)
+	}
+
+	if r.DeletedCount == 0 {
+		return NewNotFoundError("channel", "id", id)
+	}
+
+	return nil
+}
+
+func (m *MongodbChannel) SetTemplate(ctx context.Context, userId model.ID, id model.ID, template *model.Template) (*model.Channel, error) {
+	update := bson.M{"$set": bson.M{"template": template}}
+	if template == nil {
+		update = bson.M{"$unset": bson.M{"template": ""}}
+	}
+
+	r := m.coll.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, NewNotFoundError("channel", "id", id)
+		}
+
+		return nil, fmt.Errorf("error setting channel template: %w", r.Err())
+	}
+
+	var channel model.Channel
+	if err := r.Decode(&channel); err != nil {
+		return nil, fmt.Errorf("could not decode result into channel: %w", err)
+	}
+
+	return &channel, nil
+}
+
+type MongodbEscalationPolicy struct {
+	coll *mongo.Collection
+}
+
+func (m *MongodbEscalationPolicy) Add(ctx context.Context, policy *model.EscalationPolicy) error {
+	r, err := m.coll.InsertOne(ctx, policy.NoId())
+	if err != nil {
+		return fmt.Errorf("error inserting escalation policy: %w", err)
+	}
+
+	policy.Id = model.ParseIdFromObjectId(r.InsertedID.(primitive.ObjectID))
+
+	return nil
+}
+
+func (m *MongodbEscalationPolicy) Get(ctx context.Context, userId model.ID, id model.ID) (*model.EscalationPolicy, error) {
+	r := m.coll.FindOne(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+	)
+
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, NewNotFoundError("escalation policy", "id", id)
+		}
+
+		return nil, fmt.Errorf("error getting escalation policy: %w", r.Err())
+	}
+
+	var policy model.EscalationPolicy
+	if err := r.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("could not decode result into escalation policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+func (m *MongodbEscalationPolicy) GetByUserId(ctx context.Context, userId model.ID) ([]*model.EscalationPolicy, error) {
+	cursor, err := m.coll.Find(ctx, bson.M{"user_id": userId})
+	if err != nil {
+		return nil, fmt.Errorf("error reading from escalation policy collection: %w", err)
+	}
+
+	all := make([]*model.EscalationPolicy, 0)
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, fmt.Errorf("error decoding all results to escalation policy: %w", err)
+	}
+
+	return all, nil
+}
+
+func (m *MongodbEscalationPolicy) Delete(ctx context.Context, userId model.ID, id model.ID) error {
+	r, err := m.coll.DeleteOne(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("error deleting escalation policy: %w", err)
+	}
+
+	if r.DeletedCount == 0 {
+		return NewNotFoundError("escalation policy", "id", id)
+	}
+
+	return nil
+}
+
+type MongodbEscalation struct {
+	coll *mongo.Collection
+}
+
+func (m *MongodbEscalation) Add(ctx context.Context, escalation *model.Escalation) error {
+	r, err := m.coll.InsertOne(ctx, escalation.NoId())
+	if err != nil {
+		return fmt.Errorf("error inserting escalation: %w", err)
+	}
+
+	escalation.Id = model.ParseIdFromObjectId(r.InsertedID.(primitive.ObjectID))
+
+	return nil
+}
+
+func (m *MongodbEscalation) Update(ctx context.Context, escalation *model.Escalation) error {
+	r, err := m.coll.ReplaceOne(
+		ctx,
+		bson.M{"_id": escalation.Id.ObjectId()},
+		escalation.NoId(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("error updating escalation: %w", err)
+	}
+
+	if r.MatchedCount == 0 {
+		return NewNotFoundError("escalation", "id", escalation.Id)
+	}
+
+	return nil
+}
+
+func (m *MongodbEscalation) GetDue(ctx context.Context, now time.Time) ([]*model.Escalation, error) {
+	cursor, err := m.coll.Find(
+		ctx,
+		bson.M{
+			"state":   model.EscalationStateActive,
+			"next_at": bson.M{"$lte": now},
+		},
+		options.Find().SetSort(bson.D{{Key: "next_at", Value: 1}}),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading from escalation collection: %w", err)
+	}
+
+	all := make([]*model.Escalation, 0)
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, fmt.Errorf("error decoding all results to escalation: %w", err)
+	}
+
+	return all, nil
+}
+
+func (m *MongodbEscalation) GetNext(ctx context.Context) (*model.Escalation, error) {
+	r := m.coll.FindOne(
+		ctx,
+		bson.M{"state": model.EscalationStateActive},
+		options.FindOne().SetSort(bson.D{{Key: "next_at", Value: 1}}),
+	)
+
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting next escalation: %w", r.Err())
+	}
+
+	var escalation model.Escalation
+	if err := r.Decode(&escalation); err != nil {
+		return nil, fmt.Errorf("could not decode result into escalation: %w", err)
+	}
+
+	return &escalation, nil
+}
+
+type MongodbRoutingRule struct {
+	coll *mongo.Collection
+}
+
+func (m *MongodbRoutingRule) Add(ctx context.Context, rule *model.RoutingRule) error {
+	r, err := m.coll.InsertOne(ctx, rule.NoId())
+	if err != nil {
+		return fmt.Errorf("error inserting routing rule: %w", err)
+	}
+
+	rule.Id = model.ParseIdFromObjectId(r.InsertedID.(primitive.ObjectID))
+
+	return nil
+}
+
+func (m *MongodbRoutingRule) GetByUserId(ctx context.Context, userId model.ID) ([]*model.RoutingRule, error) {
+	cursor, err := m.coll.Find(
+		ctx,
+		bson.M{"user_id": userId},
+		options.Find().SetSort(bson.D{{Key: "order", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading from routing rule collection: %w", err)
+	}
+
+	all := make([]*model.RoutingRule, 0)
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, fmt.Errorf("error decoding all results to routing rule: %w", err)
+	}
+
+	return all, nil
+}
+
+func (m *MongodbRoutingRule) Delete(ctx context.Context, userId model.ID, id model.ID) error {
+	r, err := m.coll.DeleteOne(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("error deleting routing rule: %w", err)
+	}
+
+	if r.DeletedCount == 0 {
+		return NewNotFoundError("routing rule", "id", id)
+	}
+
+	return nil
+}
+
+func findStat(stats []*model.DayStat, date model.Date) *model.DayStat {
+	for _, stat := range stats {
+		if stat.Date == date {
+			return stat
+		}
+	}
+
+	return nil
+}
+
+func filterStats(stats []*model.DayStat, pick func(model.Date) bool) []model.DayStat {
+	filtered := make([]model.DayStat, 0, len(stats)/2)
+	for _, stat := range stats {
+		if pick(stat.Date) {
+			filtered = append(filtered, *stat)
+		}
+	}
+	return filtered
+}
+
+type MongodbNotificationJob struct {
+	coll *mongo.Collection
+}
+
+func (m *MongodbNotificationJob) Add(ctx context.Context, job *model.NotificationJob) error {
+	r, err := m.coll.InsertOne(ctx, job.NoId())
+	if err != nil {
+		return fmt.Errorf("error inserting notification job: %w", err)
+	}
+
+	job.Id = model.ParseIdFromObjectId(r.InsertedID.(primitive.ObjectID))
+
+	return nil
+}
+
+func (m *MongodbNotificationJob) Update(ctx context.Context, job *model.NotificationJob) error {
+	r, err := m.coll.ReplaceOne(
+		ctx,
+		bson.M{"_id": job.Id.ObjectId()},
+		job.NoId(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("error updating notification job: %w", err)
+	}
+
+	if r.MatchedCount == 0 {
+		return NewNotFoundError("notification job", "id", job.Id)
+	}
+
+	return nil
+}
+
+func (m *MongodbNotificationJob) Get(ctx context.Context, userId model.ID, id model.ID) (*model.NotificationJob, error) {
+	r := m.coll.FindOne(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+		},
+	)
+
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, NewNotFoundError("notification job", "id", id)
+		}
+
+		return nil, fmt.Errorf("error getting notification job: %w", r.Err())
+	}
+
+	var job model.NotificationJob
+	if err := r.Decode(&job); err != nil {
+		return nil, fmt.Errorf("could not decode result into notification job: %w", err)
+	}
+
+	return &job, nil
+}
+
+func (m *MongodbNotificationJob) GetByUserId(ctx context.Context, userId model.ID, status model.NotificationJobStatus, limit int) ([]*model.NotificationJob, error) {
+	filter := bson.M{"user_id": userId}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	cursor, err := m.coll.Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(int64(limit)),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading from notification job collection: %w", err)
+	}
+
+	all := make([]*model.NotificationJob, 0)
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, fmt.Errorf("error decoding all results to notification job: %w", err)
+	}
+
+	return all, nil
+}
+
+func (m *MongodbNotificationJob) Claim(ctx context.Context, now time.Time, lease time.Duration) (*model.NotificationJob, error) {
+	r := m.coll.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"status":          model.NotificationJobStatusPending,
+			"next_attempt_at": bson.M{"$lte": now},
+		},
+		bson.M{
+			"$inc": bson.M{"attempts": 1},
+			"$set": bson.M{"next_attempt_at": now.Add(lease)},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	)
+
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error claiming notification job: %w", r.Err())
+	}
+
+	var job model.NotificationJob
+	if err := r.Decode(&job); err != nil {
+		return nil, fmt.Errorf("could not decode result into notification job: %w", err)
+	}
+
+	return &job, nil
+}
+
+func (m *MongodbNotificationJob) GetNext(ctx context.Context) (*model.NotificationJob, error) {
+	r := m.coll.FindOne(
+		ctx,
+		bson.M{"status": model.NotificationJobStatusPending},
+		options.FindOne().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}),
+	)
+
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting next notification job: %w", r.Err())
+	}
+
+	var job model.NotificationJob
+	if err := r.Decode(&job); err != nil {
+		return nil, fmt.Errorf("could not decode result into notification job: %w", err)
+	}
+
+	return &job, nil
+}
+
+func (m *MongodbNotificationJob) GetTriggeredChannelIds(ctx context.Context, userId model.ID, alertIds []model.ID) ([]model.ID, error) {
+	if len(alertIds) == 0 {
+		return make([]model.ID, 0), nil
+	}
+
+	values, err := m.coll.Distinct(
+		ctx,
+		"channel_id",
+		bson.M{
+			"user_id":  userId,
+			"alert_id": bson.M{"$in": alertIds},
+			"type":     bson.M{"$nin": model.FollowUpAlertTypes},
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting channels of notification jobs: %w", err)
+	}
+
+	result := make([]model.ID, 0, len(values))
+	for _, v := range values {
+		id, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected channel id %v", v)
+		}
+		result = append(result, model.ID(id))
+	}
+
+	return result, nil
+}
+
+func (m *MongodbNotificationJob) Redrive(ctx context.Context, userId model.ID, id model.ID, at time.Time) (*model.NotificationJob, error) {
+	r := m.coll.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"_id":     id.ObjectId(),
+			"user_id": userId,
+			"status":  model.NotificationJobStatusDead,
+		},
+		bson.M{
+			"$set": bson.M{
+				"status":          model.NotificationJobStatusPending,
+				"attempts":        0,
+				"next_attempt_at": at,
+			},
+			"$unset": bson.M{"completed_at": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	if r.Err() != nil {
+		if r.Err() == mongo.ErrNoDocuments {
+			return nil, NewNotFoundError("dead notification job", "id", id)
+		}
+
+		return nil, fmt.Errorf("error re-driving notification job: %w", r.Err())
+	}
+
+	var job model.NotificationJob
+	if err := r.Decode(&job); err != nil {
+		return nil, fmt.Errorf("could not decode result into notification job: %w", err)
+	}
+
+	return &job, nil
 }
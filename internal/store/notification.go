@@ -0,0 +1,27 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+type NotificationJob interface {
+	Add(context.Context, *model.NotificationJob) error
+	Update(context.Context, *model.NotificationJob) error
+	Get(ctx context.Context, userId model.ID, id model.ID) (*model.NotificationJob, error)
+	// GetByUserId returns at most limit jobs of the user with the status, the latest first. empty status matches all jobs
+	GetByUserId(ctx context.Context, userId model.ID, status model.NotificationJobStatus, limit int) ([]*model.NotificationJob, error)
+	// Claim returns the pending job of any user that is due at now, the earliest first, or nil if there is none.
+	// the attempts of the job are incremented and its next attempt is postponed until lease passes,
+	// so it is not claimed again unless the process that claimed it stops before updating it
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (*model.NotificationJob, error)
+	// GetNext returns the pending job with the earliest next attempt, or nil if there is none
+	GetNext(ctx context.Context) (*model.NotificationJob, error)
+	// GetTriggeredChannelIds returns the channels that jobs raised any of the alerts on, whatever their status.
+	// the follow-ups of the alerts are sent to them, so the integrations that track incidents are updated
+	GetTriggeredChannelIds(ctx context.Context, userId model.ID, alertIds []model.ID) ([]model.ID, error)
+	// Redrive makes the dead job pending again, with no attempts
+	Redrive(ctx context.Context, userId model.ID, id model.ID, at time.Time) (*model.NotificationJob, error)
+}
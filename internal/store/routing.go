@@ -0,0 +1,14 @@
+package store
+
+import (
+	"context"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+type RoutingRule interface {
+	Add(context.Context, *model.RoutingRule) error
+	// GetByUserId returns the routing rules of the user sorted by order
+	GetByUserId(context.Context, model.ID) ([]*model.RoutingRule, error)
+	Delete(ctx context.Context, userId model.ID, id model.ID) error
+}
@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+type Silence interface {
+	Add(context.Context, *model.Silence) error
+	// GetByUserId returns the silences of the user, the latest first. expired silences are only returned if withExpired is set
+	GetByUserId(ctx context.Context, userId model.ID, now time.Time, withExpired bool) ([]*model.Silence, error)
+	// Expire ends the silence at 'at', if it has not already ended
+	Expire(ctx context.Context, userId model.ID, id model.ID, at time.Time) (*model.Silence, error)
+	// GetActive returns the silences of the user that are active at 'at'
+	GetActive(ctx context.Context, userId model.ID, at time.Time) ([]*model.Silence, error)
+}
@@ -0,0 +1,15 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/MeysamBavi/http-monitoring/internal/model"
+)
+
+type Stat interface {
+	// Add increments the bucket of the given resolution and start, creating it if it does not exist
+	Add(ctx context.Context, userId model.ID, urlId model.ID, stat model.Stat) (model.Stat, error)
+	// Get returns the buckets of the given resolution starting in [from, to), sorted by start
+	Get(ctx context.Context, userId model.ID, urlId model.ID, resolution model.Resolution, from time.Time, to time.Time) ([]model.Stat, error)
+}
@@ -8,6 +8,15 @@ type Store interface {
 	User() User
 	Url() Url
 	Alert() Alert
+	Stat() Stat
+	Incident() Incident
+	Maintenance() Maintenance
+	Silence() Silence
+	Channel() Channel
+	EscalationPolicy() EscalationPolicy
+	Escalation() Escalation
+	RoutingRule() RoutingRule
+	NotificationJob() NotificationJob
 }
 
 type NotFoundError string
@@ -35,3 +44,10 @@ func (e DuplicateError) Error() string {
 func NewDuplicateError(typ, field string, value any) error {
 	return DuplicateError(fmt.Sprintf("%s with %s=%v already exists", typ, field, value))
 }
+
+// ConflictError means the stored entity was changed, so the update does not apply to it anymore
+type ConflictError string
+
+func (e ConflictError) Error() string {
+	return string(e)
+}
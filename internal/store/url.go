@@ -13,7 +13,10 @@ type Url interface {
 	GetByUserId(context.Context, model.ID) ([]*model.URL, error)
 	GetDayStats(ctx context.Context, userId model.ID, id model.ID, dateFilter func(model.Date) bool) ([]model.DayStat, error)
 	Add(context.Context, *model.URL) error
-	UpdateStat(ctx context.Context, userId model.ID, id model.ID, stat model.DayStat) (*model.URL, model.DayStat, error)
+	// UpdateStat adds the counts of stat to the day stat of its date, and records checkedAt as the last check of the url
+	UpdateStat(ctx context.Context, userId model.ID, id model.ID, stat model.DayStat, checkedAt time.Time) (*model.URL, model.DayStat, error)
+	// SetPaused pauses or resumes monitoring of the url
+	SetPaused(ctx context.Context, userId model.ID, id model.ID, paused bool) (*model.URL, error)
 }
 
 type UrlChangeEvent struct {
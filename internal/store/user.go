@@ -10,4 +10,5 @@ type User interface {
 	Get(context.Context, model.ID) (*model.User, error)
 	GetByUsername(context.Context, string) (*model.User, error)
 	Add(context.Context, *model.User) error
+	SetTimeZone(ctx context.Context, id model.ID, timeZone string) (*model.User, error)
 }
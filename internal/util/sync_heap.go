@@ -11,8 +11,23 @@ type CustomHeapInterface[T any] interface {
 }
 
 type SyncHeap[T any] struct {
-	h     CustomHeapInterface[T]
-	mutex sync.Mutex
+	h       CustomHeapInterface[T]
+	mutex   sync.Mutex
+	changes chan struct{}
+}
+
+// Changes is signalled after the heap is changed. a pending signal is not repeated,
+// so a reader wakes up once for any number of changes since it last read the heap
+func (sh *SyncHeap[T]) Changes() <-chan struct{} {
+	return sh.changes
+}
+
+// called while holding the lock, so a reader that is signalled sees the change
+func (sh *SyncHeap[T]) changed() {
+	select {
+	case sh.changes <- struct{}{}:
+	default:
+	}
 }
 
 func (sh *SyncHeap[T]) Peek() T {
@@ -22,11 +37,44 @@ func (sh *SyncHeap[T]) Peek() T {
 	return sh.h.Peek()
 }
 
+// TryPeek returns the top of the heap, or false if the heap is empty
+func (sh *SyncHeap[T]) TryPeek() (T, bool) {
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	if sh.h.Len() == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return sh.h.Peek(), true
+}
+
+// Do calls f while holding the lock of the heap.
+// it is used when the index of an element must not change between reading it and using it.
+// the heap is considered changed after f returns
+func (sh *SyncHeap[T]) Do(f func(h CustomHeapInterface[T])) {
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	f(sh.h)
+	sh.changed()
+}
+
+// View calls f while holding the lock of the heap, for reading the heap or its elements. it is not a change
+func (sh *SyncHeap[T]) View(f func(h CustomHeapInterface[T])) {
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	f(sh.h)
+}
+
 func (sh *SyncHeap[T]) Fix(i int) {
 	sh.mutex.Lock()
 	defer sh.mutex.Unlock()
 
 	heap.Fix(sh.h, i)
+	sh.changed()
 }
 
 func (sh *SyncHeap[T]) Push(x T) {
@@ -34,12 +82,14 @@ func (sh *SyncHeap[T]) Push(x T) {
 	defer sh.mutex.Unlock()
 
 	heap.Push(sh.h, x)
+	sh.changed()
 }
 
 func (sh *SyncHeap[T]) Pop() T {
 	sh.mutex.Lock()
 	defer sh.mutex.Unlock()
 
+	defer sh.changed()
 	return heap.Pop(sh.h).(T)
 }
 
@@ -52,6 +102,7 @@ func (sh *SyncHeap[T]) Len() int {
 
 func NewSyncHeap[T any](h CustomHeapInterface[T]) *SyncHeap[T] {
 	return &SyncHeap[T]{
-		h: h,
+		h:       h,
+		changes: make(chan struct{}, 1),
 	}
 }